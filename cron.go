@@ -0,0 +1,493 @@
+package gopool
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule get the next execute time after the given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// OverlapPolicy what to do when the previous run still active
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skip this run if the previous run still active
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue run after the previous run finish
+	OverlapQueue
+	// OverlapConcurrent run concurrently with the previous run
+	OverlapConcurrent
+)
+
+// ErrCronStopped cron already stopped
+var ErrCronStopped = errors.New("cron stopped")
+
+// star bit is set when the field is '*' or '?'
+const starBit = 1 << 63
+
+// cronField the bounds of a cron field
+type cronField struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondField = cronField{0, 59, nil}
+	minuteField = cronField{0, 59, nil}
+	hourField   = cronField{0, 23, nil}
+	domField    = cronField{1, 31, nil}
+	monthField  = cronField{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{0, 6, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// cronSchedule the bits of each field are the matched values
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+}
+
+// everySchedule execute every fixed duration
+type everySchedule struct {
+	every time.Duration
+}
+
+// Next get the time after every duration
+func (s *everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every)
+}
+
+// ParseCron parse the cron spec, 5 fields 'minute hour dom month dow',
+// 6 fields 'second minute hour dom month dow', descriptors like '@daily'
+// and '@every <duration>' are supported
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec '%s': %s", spec, err.Error())
+		}
+		if every <= 0 {
+			return nil, fmt.Errorf("invalid cron spec '%s': duration must be positive", spec)
+		}
+		return &everySchedule{every: every}, nil
+	}
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron spec '%s': expected 5 or 6 fields, got %d", spec, len(fields))
+	}
+
+	var (
+		schedule = &cronSchedule{}
+		err      error
+	)
+	for i, f := range []struct {
+		bits  *uint64
+		field cronField
+	}{
+		{&schedule.second, secondField},
+		{&schedule.minute, minuteField},
+		{&schedule.hour, hourField},
+		{&schedule.dom, domField},
+		{&schedule.month, monthField},
+		{&schedule.dow, dowField},
+	} {
+		if *f.bits, err = parseCronField(fields[i], f.field); err != nil {
+			return nil, fmt.Errorf("invalid cron spec '%s': %s", spec, err.Error())
+		}
+	}
+	return schedule, nil
+}
+
+// parseCronField parse comma separated ranges like '*', '1-5', '*/2', '3-30/3'
+func parseCronField(expr string, field cronField) (uint64, error) {
+	var bits uint64
+	// sunday can be 7 in day of week, folded into 0 after expanded
+	bound := field
+	if field.names != nil && field.max == 6 {
+		bound.max = 7
+	}
+	for _, part := range strings.Split(expr, ",") {
+		var (
+			start, end      = field.min, field.max
+			step       uint = 1
+			rangeExpr       = part
+			err        error
+		)
+		if index := strings.Index(part, "/"); index >= 0 {
+			rangeExpr = part[:index]
+			if step, err = parseCronValue(part[index+1:], cronField{1, field.max, nil}); err != nil {
+				return 0, err
+			}
+		}
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			if step == 1 {
+				bits |= starBit
+			}
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			if start, err = parseCronValue(bounds[0], bound); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], bound); err != nil {
+				return 0, err
+			}
+			// 'sun-7' is sunday only, not from sunday to sunday
+			if end > field.max && strings.EqualFold(bounds[0], "sun") {
+				start = end
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range '%s'", rangeExpr)
+			}
+		default:
+			if start, err = parseCronValue(rangeExpr, bound); err != nil {
+				return 0, err
+			}
+			// 'n/step' means from n to max
+			if !strings.Contains(part, "/") || start > end {
+				end = start
+			}
+		}
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	if bound.max > field.max && bits&(1<<bound.max) > 0 {
+		bits = bits&^(1<<bound.max) | 1
+	}
+	return bits, nil
+}
+
+func parseCronValue(expr string, field cronField) (uint, error) {
+	if value, ok := field.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+	value, err := strconv.ParseUint(expr, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", expr)
+	}
+	if uint(value) < field.min || uint(value) > field.max {
+		return 0, fmt.Errorf("value '%s' out of range [%d, %d]", expr, field.min, field.max)
+	}
+	return uint(value), nil
+}
+
+// Next get the next matched time, zero time if not found in 5 years
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// start from the next second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+	// whether the time is truncated
+	added := false
+
+WRAP:
+	for t.Year() <= yearLimit {
+		for s.month&(1<<uint(t.Month())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue WRAP
+			}
+		}
+		for !s.dayMatches(t) {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}
+			t = t.AddDate(0, 0, 1)
+			if t.Day() == 1 {
+				continue WRAP
+			}
+		}
+		for s.hour&(1<<uint(t.Hour())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+			}
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue WRAP
+			}
+		}
+		for s.minute&(1<<uint(t.Minute())) == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Minute)
+			}
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue WRAP
+			}
+		}
+		for s.second&(1<<uint(t.Second())) == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Second)
+			}
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue WRAP
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches if both dom and dow are restricted, match either of them
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) > 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) > 0
+	if s.dom&starBit > 0 || s.dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// CronEntry the snapshot of a scheduled job or pipeline
+type CronEntry struct {
+	ID      int
+	Spec    string
+	Name    string
+	Overlap OverlapPolicy
+	// the next execute time
+	Next time.Time
+	// the last execute time
+	Prev time.Time
+	// the number of active runs
+	Active int
+	// the number of runs wait for the previous run finish
+	Queued int
+}
+
+type cronEntry struct {
+	id       int
+	spec     string
+	schedule Schedule
+	overlap  OverlapPolicy
+	job      *Job
+	pipeline *Pipeline
+	next     time.Time
+	prev     time.Time
+	timer    *time.Timer
+	active   int
+	queued   int
+	removed  bool
+}
+
+func (e *cronEntry) name() string {
+	if e.job != nil {
+		return e.job.Name
+	}
+	return e.pipeline.Name
+}
+
+// Cron add jobs or pipelines into pool on schedule,
+// every run execute a fresh copy of the job or pipeline
+type Cron struct {
+	pool    *Pool
+	entries map[int]*cronEntry
+	nextID  int
+	stopped bool
+	m       sync.Mutex
+}
+
+// NewCron get a new cron which add jobs into the pool
+func NewCron(pool *Pool) *Cron {
+	return &Cron{
+		pool:    pool,
+		entries: make(map[int]*cronEntry),
+	}
+}
+
+// AddJob add the job into pool on schedule, return the entry id
+func (c *Cron) AddJob(spec string, job *Job, overlap OverlapPolicy) (int, error) {
+	return c.add(spec, &cronEntry{job: job, overlap: overlap})
+}
+
+// AddPipeline add the pipeline into pool on schedule, return the entry id
+func (c *Cron) AddPipeline(spec string, pipeline *Pipeline, overlap OverlapPolicy) (int, error) {
+	return c.add(spec, &cronEntry{pipeline: pipeline, overlap: overlap})
+}
+
+func (c *Cron) add(spec string, entry *cronEntry) (int, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return 0, err
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.stopped {
+		return 0, ErrCronStopped
+	}
+	c.nextID++
+	entry.id = c.nextID
+	entry.spec = spec
+	entry.schedule = schedule
+	c.entries[entry.id] = entry
+	c.schedule(entry)
+	return entry.id, nil
+}
+
+// Remove remove the entry, the active runs will not be stopped
+func (c *Cron) Remove(id int) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	entry, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	c.remove(entry)
+	return true
+}
+
+// Entries get all entries order by id
+func (c *Cron) Entries() []CronEntry {
+	c.m.Lock()
+	defer c.m.Unlock()
+	var entries []CronEntry
+	for id := 1; id <= c.nextID; id++ {
+		entry, ok := c.entries[id]
+		if !ok {
+			continue
+		}
+		entries = append(entries, CronEntry{
+			ID:      entry.id,
+			Spec:    entry.spec,
+			Name:    entry.name(),
+			Overlap: entry.overlap,
+			Next:    entry.next,
+			Prev:    entry.prev,
+			Active:  entry.active,
+			Queued:  entry.queued,
+		})
+	}
+	return entries
+}
+
+// Stop remove all entries, the active runs will not be stopped
+func (c *Cron) Stop() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.stopped = true
+	for _, entry := range c.entries {
+		c.remove(entry)
+	}
+}
+
+func (c *Cron) remove(entry *cronEntry) {
+	entry.removed = true
+	entry.queued = 0
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	delete(c.entries, entry.id)
+}
+
+// schedule arm the timer for the next execute time
+func (c *Cron) schedule(entry *cronEntry) {
+	now := time.Now()
+	entry.next = entry.schedule.Next(now)
+	if entry.next.IsZero() {
+		return
+	}
+	entry.timer = time.AfterFunc(entry.next.Sub(now), func() {
+		c.fire(entry)
+	})
+}
+
+func (c *Cron) fire(entry *cronEntry) {
+	c.m.Lock()
+	if entry.removed {
+		c.m.Unlock()
+		return
+	}
+	entry.prev = entry.next
+	c.schedule(entry)
+	if entry.active > 0 {
+		switch entry.overlap {
+		case OverlapSkip:
+			c.m.Unlock()
+			c.pool.sendEvent(EventLevelWarring,
+				fmt.Sprintf("cron '%s' skipped, previous run still active", entry.name()))
+			return
+		case OverlapQueue:
+			entry.queued++
+			c.m.Unlock()
+			c.pool.sendEvent(EventLevelInfo,
+				fmt.Sprintf("cron '%s' queued, previous run still active", entry.name()))
+			return
+		}
+	}
+	entry.active++
+	c.m.Unlock()
+	c.start(entry)
+}
+
+// start add a fresh copy of the job or pipeline into pool
+func (c *Cron) start(entry *cronEntry) {
+	var err error
+	c.pool.sendEvent(EventLevelDebug, fmt.Sprintf("cron '%s' start", entry.name()))
+	if entry.job != nil {
+		job := entry.job.clone()
+		job.tracker = &runTracker{done: func() { c.finish(entry) }}
+		// finish is called once even if the job not added
+		job.tracker.add()
+		err = c.pool.AddJob(job)
+		job.tracker.finish()
+	} else {
+		run := entry.pipeline.newRun()
+		run.run.onDone = func() { c.finish(entry) }
+		err = c.pool.AddPipeline(run.run)
+	}
+	if err != nil {
+		c.pool.sendEvent(EventLevelError,
+			fmt.Sprintf("cron '%s' start fail[%s]", entry.name(), err.Error()))
+	}
+}
+
+// finish the run finished, start the queued run
+func (c *Cron) finish(entry *cronEntry) {
+	c.m.Lock()
+	entry.active--
+	if entry.queued == 0 {
+		c.m.Unlock()
+		return
+	}
+	entry.queued--
+	entry.active++
+	c.m.Unlock()
+	go c.start(entry)
+}
@@ -0,0 +1,140 @@
+package gopool
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2021, 11, 1, 10, 30, 15, 0, time.UTC) // monday
+	for _, c := range []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2021, 11, 1, 10, 31, 0, 0, time.UTC)},
+		{"*/10 * * * * *", time.Date(2021, 11, 1, 10, 30, 20, 0, time.UTC)},
+		{"0 12 * * *", time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2021, 11, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2021, 11, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 5-7", time.Date(2021, 11, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat-7", time.Date(2021, 11, 6, 0, 0, 0, 0, time.UTC)},
+		{"15,45 10 * * *", time.Date(2021, 11, 1, 10, 45, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2021, 11, 1, 10, 31, 45, 0, time.UTC)},
+	} {
+		schedule, err := ParseCron(c.spec)
+		if err != nil {
+			t.Errorf("parse '%s' fail: %s", c.spec, err.Error())
+			continue
+		}
+		if next := schedule.Next(base); !next.Equal(c.next) {
+			t.Errorf("'%s' next %s, want %s", c.spec, next, c.next)
+		}
+	}
+
+	for spec, dow := range map[string]uint64{
+		"* * * * 5-7":   1<<5 | 1<<6 | 1,
+		"* * * * sat-7": 1<<6 | 1,
+		"* * * * 0-7/2": 1 | 1<<2 | 1<<4 | 1<<6,
+		"* * * * 1-7/2": 1<<1 | 1<<3 | 1<<5 | 1,
+		"* * * * 7-7":   1,
+		"* * * * sun-7": 1,
+		"* * * * 5,7":   1<<5 | 1,
+	} {
+		if schedule, err := ParseCron(spec); err != nil || schedule.(*cronSchedule).dow != dow {
+			t.Errorf("parse '%s' dow %v, want %b", spec, schedule, dow)
+		}
+	}
+
+	for _, spec := range []string{"", "* * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "@every -1s", "@every abc"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("parse '%s' should fail", spec)
+		}
+	}
+}
+
+func TestCronJob(t *testing.T) {
+	var count int32
+	p := NewPool(10, 2)
+	cron := NewCron(p)
+	job := NewJob("cron", &testJob{}).WithOnce().WithResultCallback(func(result interface{}, err error) {
+		atomic.AddInt32(&count, 1)
+	})
+	id, err := cron.AddJob("@every 20ms", job, OverlapConcurrent)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if entries := cron.Entries(); len(entries) != 1 || entries[0].ID != id || entries[0].Name != "cron" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+	time.Sleep(110 * time.Millisecond)
+	if !cron.Remove(id) || len(cron.Entries()) != 0 {
+		t.Error("remove entry fail")
+	}
+	cron.Stop()
+	p.Close("finish")
+	if atomic.LoadInt32(&count) < 3 {
+		t.Errorf("cron job executed %d times", count)
+	}
+	if job.IsTrigged() {
+		t.Error("the define job should not be trigged")
+	}
+}
+
+func TestCronOverlapSkip(t *testing.T) {
+	var running, maxRunning, count int32
+	p := NewPool(10, 4)
+	cron := NewCron(p)
+	job1 := NewJob("job1", &funcJob{f: func() {
+		current := atomic.AddInt32(&running, 1)
+		if current > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, current)
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}})
+	job2 := NewJob("job2", &testJob{}).WithResultCallback(func(result interface{}, err error) {
+		atomic.AddInt32(&count, 1)
+	})
+	job2.After(job1)
+	pipeline, err := NewPipeline("cron", job1, job2)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if _, err := cron.AddPipeline("@every 10ms", pipeline, OverlapSkip); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	time.Sleep(200 * time.Millisecond)
+	cron.Stop()
+	p.Close("finish")
+	if atomic.LoadInt32(&maxRunning) != 1 {
+		t.Errorf("max running %d, want 1", maxRunning)
+	}
+	if count := atomic.LoadInt32(&count); count < 2 || count > 4 {
+		t.Errorf("pipeline executed %d times", count)
+	}
+}
+
+func TestCronOverlapQueue(t *testing.T) {
+	var count int32
+	p := NewPool(10, 4)
+	cron := NewCron(p)
+	job := NewJob("slow", &funcJob{f: func() {
+		time.Sleep(30 * time.Millisecond)
+		atomic.AddInt32(&count, 1)
+	}})
+	id, _ := cron.AddJob("@every 10ms", job, OverlapQueue)
+	time.Sleep(55 * time.Millisecond)
+	entries := cron.Entries()
+	if len(entries) != 1 || entries[0].ID != id || entries[0].Active != 1 || entries[0].Queued == 0 {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	cron.Stop()
+	p.Close("finish")
+}
@@ -8,103 +8,37 @@ import (
 	gopool "github.com/wksw/go-pool"
 )
 
-type jobA struct {
-	Name string
-	A    time.Duration
-}
-
-type jobB struct {
-	Name string
-	B    int
-}
-
-type jobC struct {
-	Name string
-	C    int64
-}
-
-type jobD struct {
-	Name string
-	D    string
-}
-
-type jobE struct {
-	Name string
-	E    string
-}
-
-var _ gopool.JobHandler = &jobA{}
-var _ gopool.JobHandler = &jobB{}
-var _ gopool.JobHandler = &jobC{}
-var _ gopool.JobHandler = &jobD{}
-var _ gopool.JobHandler = &jobE{}
-
-func (j *jobA) Handle() (interface{}, error) {
-	time.Sleep(j.A)
-	fmt.Println(j.Name, "花了", j.A.Seconds(), "秒", time.Now().Unix())
-	return nil, nil
-}
-
-func (j *jobB) Handle() (interface{}, error) {
-	time.Sleep(time.Duration(j.B) * time.Second)
-	fmt.Println(j.Name, "花了", j.B, "秒", time.Now().Unix())
-	return nil, nil
-}
-
-func (j *jobC) Handle() (interface{}, error) {
-	time.Sleep(time.Duration(j.C) * time.Second)
-	fmt.Println(j.Name, "花了", j.C, "秒", time.Now().Unix())
-	return nil, nil
-}
-
-func (j *jobD) Handle() (interface{}, error) {
-	fmt.Println(j.Name, j.D, time.Now().Unix())
-	return nil, nil
-}
-
-func (j *jobE) Handle() (interface{}, error) {
-	fmt.Println(j.Name, j.E, time.Now().Unix())
-	return nil, nil
+// sleep print the job name after sleep d
+func sleep(name string, d time.Duration) func() {
+	return func() {
+		time.Sleep(d)
+		fmt.Println(name, "花了", d.Seconds(), "秒", time.Now().Unix())
+	}
 }
 
 func main() {
 
-	jobA := gopool.NewJob("起床", &jobA{Name: "起床", A: time.Second})
-	jobB := gopool.NewJob("洗脸", &jobB{Name: "洗脸", B: 2})
-	jobC := gopool.NewJob("刷牙", &jobC{Name: "刷牙", C: 3})
-	jobD := gopool.NewJob("深呼吸", &jobD{Name: "深呼吸", D: "一大口"})
-	jobE := gopool.NewJob("上班", &jobE{Name: "上班", E: ""})
-
-	if err := jobB.When(func(self *gopool.Job) bool {
-		for _, job := range self.GetUpstreams() {
-			if job.GetStatus() != gopool.JobSuccess {
-				return false
-			}
-		}
-		return true
-	}).After(jobA); err != nil {
+	jobA := gopool.NewJob("起床", gopool.SimpleFunc(sleep("起床", time.Second)))
+	jobB := gopool.NewJob("洗脸", gopool.SimpleFunc(sleep("洗脸", 2*time.Second)))
+	jobC := gopool.NewJob("刷牙", gopool.SimpleFunc(sleep("刷牙", 3*time.Second)))
+	jobD := gopool.NewFuncJob("深呼吸", func() (interface{}, error) {
+		fmt.Println("深呼吸", "一大口", time.Now().Unix())
+		return nil, nil
+	})
+	jobE := gopool.NewJob("上班", gopool.ErrorFunc(func() error {
+		fmt.Println("上班", time.Now().Unix())
+		return nil
+	}))
+
+	if err := jobB.WithTriggerRule(gopool.TriggerAllSuccess).After(jobA); err != nil {
 		log.Fatal("A -> B ", err.Error())
 	}
 
-	if err := jobC.When(func(self *gopool.Job) bool {
-		for _, job := range self.GetUpstreams() {
-			if job.GetStatus() != gopool.JobSuccess {
-				return false
-			}
-		}
-		return true
-	}).After(jobA); err != nil {
+	if err := jobC.WithTriggerRule(gopool.TriggerAllSuccess).After(jobA); err != nil {
 		log.Fatal("A -> C ", err.Error())
 	}
 
-	if err := jobE.When(func(self *gopool.Job) bool {
-		for _, job := range self.GetUpstreams() {
-			if job.GetStatus() != gopool.JobSuccess {
-				return false
-			}
-		}
-		return true
-	}).After(jobB, jobC); err != nil {
+	if err := jobE.WithTriggerRule(gopool.TriggerAllSuccess).After(jobB, jobC); err != nil {
 		log.Fatal("A, B, C, D -> E ", err.Error())
 	}
 
@@ -0,0 +1,126 @@
+package gopool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the fill color of the job by status in the exported graph
+var statusColors = map[int]string{
+	JobPendding: "#ffffff",
+	JobRunning:  "#add8e6",
+	JobSuccess:  "#98fb98",
+	JobFail:     "#fa8072",
+	JobCancled:  "#d3d3d3",
+	JobTimeout:  "#ffa500",
+	JobSkipped:  "#f5f5f5",
+}
+
+// statusName the name of the job status
+func statusName(status int) string {
+	switch status {
+	case JobRunning:
+		return "running"
+	case JobSuccess:
+		return "success"
+	case JobFail:
+		return "fail"
+	case JobCancled:
+		return "cancled"
+	case JobTimeout:
+		return "timeout"
+	case JobSkipped:
+		return "skipped"
+	}
+	return "pendding"
+}
+
+// nodes get the jobs, their node ids and the edges between
+// the node ids, every job has one node
+func (p *Pipeline) nodes() ([]*Job, map[*Job]string, [][2]string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	ids := make(map[*Job]string, len(p.UniqueJobs))
+	for index, job := range p.UniqueJobs {
+		ids[job] = fmt.Sprintf("n%d", index)
+	}
+	var edges [][2]string
+	for _, job := range p.UniqueJobs {
+		for _, children := range job.childrens {
+			if id, ok := ids[children]; ok {
+				edges = append(edges, [2]string{ids[job], id})
+			}
+		}
+	}
+	return append([]*Job{}, p.UniqueJobs...), ids, edges
+}
+
+// DOT export the graph in Graphviz DOT, every job is a node,
+// fill the nodes by the current job status if status is true
+func (p *Pipeline) DOT(status bool) string {
+	jobs, ids, edges := p.nodes()
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", p.Name)
+	b.WriteString("\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, job := range jobs {
+		attrs := []string{fmt.Sprintf("label=%q", job.Name)}
+		if job.sub != nil {
+			attrs = append(attrs, "shape=box3d")
+		}
+		if status {
+			attrs = append(attrs, "style=filled",
+				fmt.Sprintf("fillcolor=%q", statusColors[job.GetStatus()]))
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", ids[job], strings.Join(attrs, ", "))
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", edge[0], edge[1])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid export the graph in Mermaid flowchart, every job is a node,
+// fill the nodes by the current job status if status is true
+func (p *Pipeline) Mermaid(status bool) string {
+	jobs, ids, edges := p.nodes()
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, job := range jobs {
+		label := strings.ReplaceAll(job.Name, `"`, "#quot;")
+		if job.sub != nil {
+			fmt.Fprintf(&b, "\t%s[[\"%s\"]]\n", ids[job], label)
+		} else {
+			fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[job], label)
+		}
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "\t%s --> %s\n", edge[0], edge[1])
+	}
+	if !status {
+		return b.String()
+	}
+	classes := make(map[int][]string)
+	for _, job := range jobs {
+		status := job.GetStatus()
+		classes[status] = append(classes[status], ids[job])
+	}
+	for status := JobPendding; status <= JobSkipped; status++ {
+		if len(classes[status]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\tclassDef %s fill:%s\n", statusName(status), statusColors[status])
+		fmt.Fprintf(&b, "\tclass %s %s\n", strings.Join(classes[status], ","), statusName(status))
+	}
+	return b.String()
+}
+
+// DOT export the graph of the run in Graphviz DOT, see Pipeline.DOT
+func (r *PipelineRun) DOT(status bool) string {
+	return r.run.DOT(status)
+}
+
+// Mermaid export the graph of the run in Mermaid flowchart, see Pipeline.Mermaid
+func (r *PipelineRun) Mermaid(status bool) string {
+	return r.run.Mermaid(status)
+}
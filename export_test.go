@@ -0,0 +1,91 @@
+package gopool
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newDiamondPipeline(t *testing.T) *Pipeline {
+	a := NewJob("a", &testJob{})
+	b := NewJob("b", &failJob{})
+	c := NewJob(`c "quoted"`, &testJob{})
+	d := NewJob("d", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	a.Before(b, c)
+	d.After(b, c)
+	pipeline, err := NewPipeline("diamond", a)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	return pipeline
+}
+
+func TestPipelineDOT(t *testing.T) {
+	pipeline := newDiamondPipeline(t)
+	dot := pipeline.DOT(false)
+	want := `digraph "diamond" {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a"];
+	n1 [label="b"];
+	n2 [label="d"];
+	n3 [label="c \"quoted\""];
+	n0 -> n1;
+	n0 -> n3;
+	n1 -> n2;
+	n3 -> n2;
+}
+`
+	if dot != want {
+		t.Errorf("unexpected dot %s", dot)
+	}
+
+	p := NewPool(10, 2)
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	dot = run.DOT(true)
+	for _, node := range []string{
+		`n0 [label="a", style=filled, fillcolor="#98fb98"]`,
+		`n1 [label="b", style=filled, fillcolor="#fa8072"]`,
+		`n2 [label="d", style=filled, fillcolor="#f5f5f5"]`,
+	} {
+		if !strings.Contains(dot, node) {
+			t.Errorf("node %s not found in %s", node, dot)
+		}
+	}
+	p.Close("finish")
+}
+
+func TestPipelineMermaid(t *testing.T) {
+	pipeline := newDiamondPipeline(t)
+	mermaid := pipeline.Mermaid(false)
+	want := `graph LR
+	n0["a"]
+	n1["b"]
+	n2["d"]
+	n3["c #quot;quoted#quot;"]
+	n0 --> n1
+	n0 --> n3
+	n1 --> n2
+	n3 --> n2
+`
+	if mermaid != want {
+		t.Errorf("unexpected mermaid %s", mermaid)
+	}
+
+	p := NewPool(10, 2)
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	mermaid = run.Mermaid(true)
+	for _, class := range []string{
+		"classDef success fill:#98fb98\n\tclass n0,n3 success",
+		"classDef fail fill:#fa8072\n\tclass n1 fail",
+		"classDef skipped fill:#f5f5f5\n\tclass n2 skipped",
+	} {
+		if !strings.Contains(mermaid, class) {
+			t.Errorf("class %s not found in %s", class, mermaid)
+		}
+	}
+	p.Close("finish")
+}
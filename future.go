@@ -0,0 +1,140 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	// ErrJobNotDone the job not finished yet
+	ErrJobNotDone = errors.New("job not done")
+	// ErrJobCancled the job cancled before execute
+	ErrJobCancled = errors.New("job cancled")
+	// ErrJobSkipped the job skipped in pipeline
+	ErrJobSkipped = errors.New("job skipped")
+)
+
+// the number of submitted handlers, used for job name
+var submitted uint64
+
+// Future the result handle of a submitted job
+type Future struct {
+	job *Job
+	// the done channel of the job execution
+	done <-chan struct{}
+}
+
+// Submit add the handler into pool as a job, return the future of the result
+func (p *Pool) Submit(handler JobHandler) (*Future, error) {
+	name := fmt.Sprintf("submit-%d", atomic.AddUint64(&submitted, 1))
+	return p.SubmitJob(NewJob(name, handler))
+}
+
+// SubmitJob add the job into pool, return the future of the result
+func (p *Pool) SubmitJob(job *Job) (*Future, error) {
+	if err := p.AddJob(job); err != nil {
+		return nil, err
+	}
+	return NewFuture(job), nil
+}
+
+// NewFuture get the future of the job, the future follow the
+// execution of the job added before or after the future created,
+// the job added again after finished has a new future
+func NewFuture(job *Job) *Future {
+	return &Future{job: job, done: job.getDone()}
+}
+
+// Job get the job of the future
+func (f *Future) Job() *Job {
+	return f.job
+}
+
+// Done the channel closed when the job finished or cancled
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait wait the job finished and get the result
+func (f *Future) Wait() (interface{}, error) {
+	<-f.done
+	return f.Result()
+}
+
+// WaitContext wait the job finished and get the result,
+// return the context error if the context done first
+func (f *Future) WaitContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.Result()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Result get the result without wait, return ErrJobNotDone if
+// the job not finished, ErrJobCancled if cancled before execute,
+// ErrJobSkipped if skipped in pipeline
+func (f *Future) Result() (interface{}, error) {
+	select {
+	case <-f.done:
+	default:
+		return nil, ErrJobNotDone
+	}
+	result, err := f.job.GetResult()
+	if err != nil {
+		return result, err
+	}
+	switch f.job.GetStatus() {
+	case JobCancled:
+		return nil, ErrJobCancled
+	case JobSkipped:
+		return nil, ErrJobSkipped
+	}
+	return result, nil
+}
+
+// Cancle cancle the job of the future
+func (f *Future) Cancle() {
+	f.job.Cancle()
+}
+
+// WaitAll wait all futures done, return the context error if the context done first
+func WaitAll(ctx context.Context, futures ...*Future) error {
+	for _, future := range futures {
+		select {
+		case <-future.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// WaitAny wait any of the futures done and return it's index,
+// return the context error if the context done first
+func WaitAny(ctx context.Context, futures ...*Future) (int, error) {
+	if len(futures) == 0 {
+		return -1, errors.New("no futures")
+	}
+	done := make(chan int, len(futures))
+	stop := make(chan struct{})
+	defer close(stop)
+	for index, future := range futures {
+		go func(index int, future *Future) {
+			select {
+			case <-future.Done():
+				done <- index
+			case <-stop:
+			}
+		}(index, future)
+	}
+	select {
+	case index := <-done:
+		return index, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
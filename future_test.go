@@ -0,0 +1,98 @@
+package gopool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type resultJob struct {
+	result interface{}
+	d      time.Duration
+}
+
+func (j *resultJob) Handle() (interface{}, error) {
+	time.Sleep(j.d)
+	return j.result, nil
+}
+
+func TestFutureWait(t *testing.T) {
+	p := NewPool(10, 2)
+	future, err := p.Submit(&resultJob{result: "ok", d: 10 * time.Millisecond})
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if _, err := future.Result(); err != ErrJobNotDone {
+		t.Errorf("unexpected error %v", err)
+	}
+	result, err := future.Wait()
+	if result != "ok" || err != nil {
+		t.Errorf("unexpected result %v %v", result, err)
+	}
+	p.Close("finish")
+}
+
+func TestFutureWaitContext(t *testing.T) {
+	p := NewPool(10, 2)
+	future, _ := p.Submit(&resultJob{result: "ok", d: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := future.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	cancled := NewJob("cancled", &testJob{})
+	cancled.Cancle()
+	if _, err := NewFuture(cancled).Wait(); err != ErrJobCancled {
+		t.Errorf("unexpected error %v", err)
+	}
+	p.Close("finish")
+}
+
+func TestFutureWaitAllAny(t *testing.T) {
+	p := NewPool(10, 1)
+	block := make(chan struct{})
+	slow, _ := p.SubmitJob(NewJob("slow", &funcJob{f: func() { <-block }}))
+	// the only worker is blocked, the fast job finished without execute
+	fastJob := NewJob("fast", &testJob{})
+	fastJob.Cancle()
+	fast := NewFuture(fastJob)
+	index, err := WaitAny(context.Background(), slow, fast)
+	if err != nil || index != 1 {
+		t.Errorf("index %d error %v", index, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitAll(ctx, slow, fast); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error %v", err)
+	}
+	close(block)
+	if err := WaitAll(context.Background(), slow, fast); err != nil {
+		t.Error(err.Error())
+	}
+	if _, err := fast.Result(); err != ErrJobCancled {
+		t.Errorf("unexpected error %v", err)
+	}
+	p.Close("finish")
+}
+
+func TestFutureResubmit(t *testing.T) {
+	var count int32
+	p := NewPool(10, 2)
+	job := NewFuncJob("resubmit", func() (interface{}, error) {
+		return atomic.AddInt32(&count, 1), nil
+	})
+	for want := int32(1); want <= 2; want++ {
+		future, err := p.SubmitJob(job)
+		if err != nil {
+			t.Error(err.Error())
+			t.FailNow()
+		}
+		if result, err := future.Wait(); result != want || err != nil {
+			t.Errorf("unexpected result %v %v, want %d", result, err, want)
+		}
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,44 @@
+package gopool
+
+import "context"
+
+// Input the output of an upstream job
+type Input struct {
+	Status int
+	Result interface{}
+	Err    error
+}
+
+// Inputs the outputs of the upstream jobs keyed by upstream job name
+type Inputs map[string]Input
+
+// inputsKey the context key of the inputs
+type inputsKey struct{}
+
+// InputsFromContext get the outputs of the upstream jobs from the context
+// passed to the ContextJobHandler, nil if the job not executed in pipeline
+func InputsFromContext(ctx context.Context) Inputs {
+	inputs, _ := ctx.Value(inputsKey{}).(Inputs)
+	return inputs
+}
+
+// Result get the result of the upstream job, nil if not found
+func (in Inputs) Result(name string) interface{} {
+	return in[name].Result
+}
+
+// Err get the error of the upstream job, nil if not found
+func (in Inputs) Err(name string) error {
+	return in[name].Err
+}
+
+// withInputs attach the outputs of the upstreams of the job to the context
+func withInputs(ctx context.Context, job *Job) context.Context {
+	inputs := make(Inputs, len(job.parents))
+	for _, parent := range job.parents {
+		input := Input{Status: parent.GetStatus()}
+		input.Result, input.Err = parent.GetResult()
+		inputs[parent.Name] = input
+	}
+	return context.WithValue(ctx, inputsKey{}, inputs)
+}
@@ -0,0 +1,45 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type sumJob struct{}
+
+func (j *sumJob) Handle(ctx context.Context) (interface{}, error) {
+	inputs := InputsFromContext(ctx)
+	if inputs.Err("fail") == nil {
+		return nil, errors.New("fail error not passed")
+	}
+	return inputs.Result("one").(int) + inputs.Result("two").(int), nil
+}
+
+func TestPipelineInputs(t *testing.T) {
+	p := NewPool(10, 3)
+	one := NewJob("one", &resultJob{result: 1})
+	two := NewJob("two", &resultJob{result: 2})
+	fail := NewJob("fail", &failJob{})
+	sum := NewContextJob("sum", &sumJob{}).WithTriggerRule(TriggerAllDone)
+	sum.After(one, two, fail)
+	pipeline, _ := NewPipeline("inputs", one, two, fail)
+	run, err := p.RunPipeline(pipeline)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	run.Wait(context.Background())
+	result, err := run.Job("sum").GetResult()
+	if err != nil || result != 3 {
+		t.Errorf("unexpected result %v %v", result, err)
+	}
+	p.Close("finish")
+}
+
+func TestInputsFromContext(t *testing.T) {
+	inputs := InputsFromContext(context.Background())
+	if inputs != nil || inputs.Result("none") != nil || inputs.Err("none") != nil {
+		t.Errorf("unexpected inputs %v", inputs)
+	}
+}
@@ -1,8 +1,11 @@
 package gopool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 const (
@@ -16,17 +19,79 @@ const (
 	JobFail
 	// JobCancled jobs status is cancled
 	JobCancled
+	// JobTimeout job status is timeout
+	JobTimeout
+	// JobSkipped job status is skipped, the job in pipeline
+	// decided not to execute by it's trigger rule or When condition
+	JobSkipped
 )
 
+// ErrJobTimeout job execute timeout
+var ErrJobTimeout = errors.New("job timeout")
+
 // JobHandler job need implement methods
 type JobHandler interface {
 	Handle() (interface{}, error)
 }
 
+// ContextJobHandler job need implement methods when
+// the job want to be notified of cancellation
+type ContextJobHandler interface {
+	Handle(ctx context.Context) (interface{}, error)
+}
+
+// jobHandlerAdapter adapt JobHandler to ContextJobHandler
+type jobHandlerAdapter struct {
+	handler JobHandler
+}
+
+// Handle ignore the context and call the wrapped handler
+func (a *jobHandlerAdapter) Handle(ctx context.Context) (interface{}, error) {
+	return a.handler.Handle()
+}
+
+// JobHandlerFunc adapt ordinary function to JobHandler
+type JobHandlerFunc func() (interface{}, error)
+
+// Handle call f()
+func (f JobHandlerFunc) Handle() (interface{}, error) {
+	return f()
+}
+
+// ContextJobHandlerFunc adapt ordinary function to ContextJobHandler
+type ContextJobHandlerFunc func(ctx context.Context) (interface{}, error)
+
+// Handle call f(ctx)
+func (f ContextJobHandlerFunc) Handle(ctx context.Context) (interface{}, error) {
+	return f(ctx)
+}
+
+// ErrorFunc adapt the function only return error to JobHandler
+func ErrorFunc(f func() error) JobHandlerFunc {
+	return func() (interface{}, error) {
+		return nil, f()
+	}
+}
+
+// ContextErrorFunc adapt the function only return error to ContextJobHandler
+func ContextErrorFunc(f func(ctx context.Context) error) ContextJobHandlerFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		return nil, f(ctx)
+	}
+}
+
+// SimpleFunc adapt the function without result to JobHandler
+func SimpleFunc(f func()) JobHandlerFunc {
+	return func() (interface{}, error) {
+		f()
+		return nil, nil
+	}
+}
+
 // Job job define
 type Job struct {
 	Name           string
-	handler        JobHandler
+	handler        ContextJobHandler
 	parents        []*Job
 	childrens      []*Job
 	status         int
@@ -34,10 +99,47 @@ type Job struct {
 	resultCallback func(interface{}, error)
 	err            error
 	when           func(self *Job) bool
-	m              sync.RWMutex
+	// decide whether execute by the status of upstreams
+	triggerRule TriggerRule
+	// whether the trigger rule already evaluated
+	ruleEvaluated bool
+	m             sync.RWMutex
 	// whether is trigged
 	trigged bool
 	once    bool
+	// max execute time, no limit if zero
+	timeout time.Duration
+	// retry policy when the job fail
+	retry *RetryPolicy
+	// the number of times the handler executed
+	attempts int
+	// the job with higher priority execute first in priority pool
+	priority int
+	// the failure of the job don't fail the pipeline
+	allowFailure bool
+	// the pipeline executed as this job
+	sub *Pipeline
+	// the job success in the run resumed from, not executed again
+	cached bool
+	// the job spawned this job at runtime
+	spawner *Job
+	// the registered handler name and params, used to serialize the job
+	handlerName string
+	params      map[string]interface{}
+	// track the run this job belongs to
+	tracker *runTracker
+	// the started pipeline this job belongs to
+	pipeline *Pipeline
+	// closed when the job finished or cancled
+	done chan struct{}
+	// the first attempt start time and the finish time
+	startTime time.Time
+	endTime   time.Time
+	// whether is cancled
+	cancled bool
+	// the context of the running handler
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 type jobs []*Job
@@ -57,13 +159,35 @@ func (j jobs) String() string {
 
 // NewJob get a new job
 func NewJob(name string, handler JobHandler) *Job {
+	var contextHandler ContextJobHandler
+	if handler != nil {
+		contextHandler = &jobHandlerAdapter{handler: handler}
+	}
+	return NewContextJob(name, contextHandler)
+}
+
+// NewContextJob get a new job which handler receive a context,
+// the context is cancelled when the job, it's pipeline or the pool
+// is cancelled
+func NewContextJob(name string, handler ContextJobHandler) *Job {
 	return &Job{
 		Name:    name,
 		handler: handler,
 		status:  JobPendding,
+		done:    make(chan struct{}),
 	}
 }
 
+// NewFuncJob get a new job which handler is the function
+func NewFuncJob(name string, f func() (interface{}, error)) *Job {
+	return NewJob(name, JobHandlerFunc(f))
+}
+
+// NewContextFuncJob get a new job which handler is the function receive a context
+func NewContextFuncJob(name string, f func(ctx context.Context) (interface{}, error)) *Job {
+	return NewContextJob(name, ContextJobHandlerFunc(f))
+}
+
 // WithResultCallback result callback function
 func (j *Job) WithResultCallback(handler func(interface{}, error)) *Job {
 	j.resultCallback = handler
@@ -76,12 +200,74 @@ func (j *Job) WithOnce() *Job {
 	return j
 }
 
+// WithTimeout set the max execute time of the job, the job will be
+// marked as JobTimeout and it's context will be cancelled when timeout
+func (j *Job) WithTimeout(timeout time.Duration) *Job {
+	j.timeout = timeout
+	return j
+}
+
+// WithRetry retry the job by the policy when the handler return error,
+// the job will be added into pool again after the backoff delay,
+// a cancled job or a job in closed pool will not be retried
+func (j *Job) WithRetry(policy *RetryPolicy) *Job {
+	j.retry = policy
+	return j
+}
+
+// Attempts get the number of times the job executed
+func (j *Job) Attempts() int {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.attempts
+}
+
+// WithPriority set the job priority, the job with higher priority
+// execute first when the pool use priority queue
+func (j *Job) WithPriority(priority int) *Job {
+	j.priority = priority
+	return j
+}
+
+// GetPriority get the job priority
+func (j *Job) GetPriority() int {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.priority
+}
+
+// AllowFailure the failure of the job don't fail the pipeline
+// and never fail fast, the downstreams still see it failed
+func (j *Job) AllowFailure() *Job {
+	j.allowFailure = true
+	return j
+}
+
+// IsAllowFailure whether the job allowed to fail
+func (j *Job) IsAllowFailure() bool {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.allowFailure
+}
+
 // When set when this job execute in pipeline
 func (j *Job) When(handle func(self *Job) bool) *Job {
 	j.when = handle
 	return j
 }
 
+// WithTriggerRule set the trigger rule evaluated after upstreams finished,
+// the job with trigger rule is triggered at most once
+func (j *Job) WithTriggerRule(rule TriggerRule) *Job {
+	j.triggerRule = rule
+	return j
+}
+
+// GetTriggerRule get the trigger rule of job
+func (j *Job) GetTriggerRule() TriggerRule {
+	return j.triggerRule
+}
+
 // IsOnce is one time trigger
 func (j *Job) IsOnce() bool {
 	j.m.Lock()
@@ -103,9 +289,51 @@ func (j *Job) setTrigged() bool {
 		return false
 	}
 	j.trigged = true
+	// the finished job added again notify the new execution finished
+	select {
+	case <-j.done:
+		j.done = make(chan struct{})
+	default:
+	}
 	return true
 }
 
+// getDone get the channel closed when the job finished or cancled
+func (j *Job) getDone() <-chan struct{} {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.done
+}
+
+// Cancle cancle the job, a pendding job will not be executed
+// and the context of a running job will be cancelled
+func (j *Job) Cancle() {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.cancled = true
+	if j.status == JobPendding {
+		j.status = JobCancled
+		j.closeDone()
+	}
+	if j.cancel != nil {
+		j.cancel()
+	}
+}
+
+// IsCancled is job already cancled
+func (j *Job) IsCancled() bool {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.cancled
+}
+
+// resetTrigged the job not added into pool after set trigged
+func (j *Job) resetTrigged() {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.trigged = false
+}
+
 // After execute after other jobs
 func (j *Job) After(jobs ...*Job) error {
 	for _, job := range jobs {
@@ -160,6 +388,20 @@ func (j *Job) GetResult() (interface{}, error) {
 	return j.result, j.err
 }
 
+// GetStartTime get the time job start execute, zero if not started
+func (j *Job) GetStartTime() time.Time {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.startTime
+}
+
+// GetEndTime get the time job finished, zero if not finished
+func (j *Job) GetEndTime() time.Time {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.endTime
+}
+
 // String job format
 func (j *Job) String() string {
 	return j.Name
@@ -175,12 +417,23 @@ func (j *Job) GetDownstreams() []*Job {
 	return j.childrens
 }
 
-func (j *Job) getNextExecuteJobs() []*Job {
+// getNextExecuteJobs get the downstreams should be triggered and
+// the downstreams skipped, only jobs in pipeline can be skipped
+func (j *Job) getNextExecuteJobs() ([]*Job, []*Job) {
+	if j.pipeline != nil {
+		return j.pipeline.nextJobs(j)
+	}
 	var downStreams []*Job
 	if j.when != nil && !j.when(j) {
-		return []*Job{}
+		return []*Job{}, nil
 	}
 	for _, job := range j.GetDownstreams() {
+		if job.triggerRule != TriggerDefault {
+			decided, run := job.triggerRule.evaluate(job.GetUpstreams(), false)
+			if !decided || !job.setRuleEvaluated() || !run {
+				continue
+			}
+		}
 		if job.when != nil {
 			if job.when(job) {
 				downStreams = append(downStreams, job)
@@ -189,15 +442,113 @@ func (j *Job) getNextExecuteJobs() []*Job {
 			downStreams = append(downStreams, job)
 		}
 	}
-	return downStreams
+	return downStreams, nil
+}
+
+// skip mark the pendding job skipped, return false if not pendding
+func (j *Job) skip() bool {
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.status != JobPendding {
+		return false
+	}
+	j.status = JobSkipped
+	j.closeDone()
+	return true
+}
+
+// setRuleEvaluated return false if the trigger rule already evaluated
+func (j *Job) setRuleEvaluated() bool {
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.ruleEvaluated {
+		return false
+	}
+	j.ruleEvaluated = true
+	return true
+}
+
+// clone copy the job define without the execute state and relations
+func (j *Job) clone() *Job {
+	return &Job{
+		Name:           j.Name,
+		handler:        j.handler,
+		status:         JobPendding,
+		resultCallback: j.resultCallback,
+		when:           j.when,
+		triggerRule:    j.triggerRule,
+		once:           j.once,
+		timeout:        j.timeout,
+		retry:          j.retry,
+		priority:       j.priority,
+		allowFailure:   j.allowFailure,
+		sub:            j.sub,
+		handlerName:    j.handlerName,
+		params:         j.params,
+		done:           make(chan struct{}),
+	}
+}
+
+// start mark the job running and return the context for the handler,
+// the context carry the upstream outputs and the job to spawn new jobs
+// when the job in pipeline, return false if the job already cancled
+func (j *Job) start(parent context.Context) (context.Context, bool) {
+	if j.pipeline != nil {
+		parent = withInputs(parent, j)
+		parent = context.WithValue(parent, spawnerKey{}, j)
+	}
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.cancled {
+		j.status = JobCancled
+		j.closeDone()
+		return nil, false
+	}
+	j.ctx, j.cancel = context.WithCancel(parent)
+	j.status = JobRunning
+	if j.attempts == 0 {
+		j.startTime = time.Now()
+	}
+	j.attempts++
+	return j.ctx, true
+}
+
+// prepareRetry set the job pendding when the failed attempt should be
+// retried and return the delay before next attempt
+func (j *Job) prepareRetry(err error) (time.Duration, bool) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	if err == nil || j.retry == nil || j.cancled || j.ctx.Err() != nil {
+		return 0, false
+	}
+	if !j.retry.shouldRetry(j.attempts, err) {
+		return 0, false
+	}
+	j.cancel()
+	j.ctx, j.cancel = nil, nil
+	j.err = err
+	j.status = JobPendding
+	return j.retry.delay(j.attempts), true
 }
 
 func (j *Job) setResult(result interface{}, err error) {
 	j.m.Lock()
 	defer j.m.Unlock()
+	// the handler gave up because of the cancellation
+	cancled := j.cancled || errors.Is(err, ErrPipelineCancled) ||
+		(j.ctx != nil && j.ctx.Err() != nil && errors.Is(err, context.Canceled))
+	if j.cancel != nil {
+		j.cancel()
+		j.ctx, j.cancel = nil, nil
+	}
 	j.result = result
 	j.err = err
-	if err != nil {
+	j.endTime = time.Now()
+	if errors.Is(err, ErrJobTimeout) {
+		j.status = JobTimeout
+	} else if err != nil && cancled {
+		j.status = JobCancled
+	} else if err != nil {
 		j.status = JobFail
 	} else {
 		j.status = JobSuccess
@@ -205,12 +556,19 @@ func (j *Job) setResult(result interface{}, err error) {
 	if j.resultCallback != nil {
 		j.resultCallback(result, err)
 	}
+	j.closeDone()
 }
 
-func (j *Job) setStatus(status int) {
-	j.m.Lock()
-	defer j.m.Unlock()
-	j.status = status
+// closeDone notify the job finished, must be called with lock
+func (j *Job) closeDone() {
+	if j.done == nil {
+		return
+	}
+	select {
+	case <-j.done:
+	default:
+		close(j.done)
+	}
 }
 
 func (j *Job) cycleAddedCheck() error {
@@ -1,6 +1,11 @@
 package gopool
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
 
 type testJob struct {
 	Name string
@@ -79,3 +84,150 @@ func TestAddCycle(t *testing.T) {
 	}
 
 }
+
+type contextJob struct{}
+
+func (j *contextJob) Handle(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func waitStatus(job *Job, status int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if job.GetStatus() == status {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestJobCancle(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewContextJob("contextJob", &contextJob{})
+	if err := p.AddJob(job); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if !waitStatus(job, JobRunning, time.Second) {
+		t.Error("job not running")
+		t.FailNow()
+	}
+	job.Cancle()
+	if !waitStatus(job, JobCancled, time.Second) {
+		t.Errorf("job status %d, want cancled", job.GetStatus())
+		t.FailNow()
+	}
+	if _, err := job.GetResult(); err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+	p.Close("finish")
+}
+
+func TestJobCancleBeforeRun(t *testing.T) {
+	job := NewJob("cancled", &testJob{})
+	job.Cancle()
+	p := NewPool(10, 2)
+	p.AddJob(job)
+	p.Close("finish")
+	if job.GetStatus() != JobCancled {
+		t.Errorf("job status %d, want cancled", job.GetStatus())
+	}
+}
+
+type sleepJob struct {
+	d time.Duration
+}
+
+func (j *sleepJob) Handle() (interface{}, error) {
+	time.Sleep(j.d)
+	return nil, nil
+}
+
+func TestJobTimeout(t *testing.T) {
+	var callbackErr error
+	p := NewPool(10, 2)
+	job := NewJob("timeout", &sleepJob{d: time.Second}).
+		WithTimeout(50 * time.Millisecond).
+		WithResultCallback(func(result interface{}, err error) {
+			callbackErr = err
+		})
+	onTimeout := NewJob("onTimeout", &testJob{}).When(func(self *Job) bool {
+		return job.GetStatus() == JobTimeout
+	})
+	if err := onTimeout.After(job); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	pipeline, err := NewPipeline("timeout", job, onTimeout)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	p.AddPipeline(pipeline)
+	if !waitStatus(onTimeout, JobSuccess, 500*time.Millisecond) {
+		t.Errorf("job status %d, want timeout", job.GetStatus())
+		t.FailNow()
+	}
+	if job.GetStatus() != JobTimeout || callbackErr != ErrJobTimeout {
+		t.Errorf("job status %d, err %v", job.GetStatus(), callbackErr)
+	}
+	p.Close("finish")
+}
+
+func TestJobTimeoutContext(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewContextJob("timeout", &contextJob{}).WithTimeout(50 * time.Millisecond)
+	p.AddJob(job)
+	if !waitStatus(job, JobTimeout, 500*time.Millisecond) {
+		t.Errorf("job status %d, want timeout", job.GetStatus())
+	}
+	p.Close("finish")
+}
+
+type funcJob struct {
+	f func()
+}
+
+func (j *funcJob) Handle() (interface{}, error) {
+	j.f()
+	return nil, nil
+}
+
+func TestFuncJob(t *testing.T) {
+	p := NewPool(10, 2)
+	var called bool
+	jobs := []*Job{
+		NewFuncJob("func", func() (interface{}, error) { return "func", nil }),
+		NewContextFuncJob("context", func(ctx context.Context) (interface{}, error) {
+			return "context", ctx.Err()
+		}),
+		NewJob("error", ErrorFunc(func() error { return errors.New("error") })),
+		NewContextJob("context error", ContextErrorFunc(func(ctx context.Context) error {
+			return nil
+		})),
+		NewJob("simple", SimpleFunc(func() { called = true })),
+	}
+	for _, job := range jobs {
+		if _, err := p.SubmitJob(job); err != nil {
+			t.Error(err.Error())
+		}
+	}
+	for _, job := range jobs {
+		if _, err := NewFuture(job).Wait(); err != nil && job.Name != "error" {
+			t.Errorf("job %s error %v", job, err)
+		}
+	}
+	if result, _ := jobs[0].GetResult(); result != "func" {
+		t.Errorf("unexpected result %v", result)
+	}
+	if result, _ := jobs[1].GetResult(); result != "context" {
+		t.Errorf("unexpected result %v", result)
+	}
+	if jobs[2].GetStatus() != JobFail || jobs[3].GetStatus() != JobSuccess || !called {
+		t.Errorf("error status %d context error status %d called %v",
+			jobs[2].GetStatus(), jobs[3].GetStatus(), called)
+	}
+	p.Close("finish")
+}
@@ -1,15 +1,96 @@
 package gopool
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	// PipelineSuccess all triggered jobs success
+	PipelineSuccess = iota
+	// PipelineFailed some jobs failed or timeout
+	PipelineFailed
+	// PipelineCancled the pipeline or some jobs cancled
+	PipelineCancled
+)
+
+// ErrPipelineNotStarted the pipeline not added into pool
+var ErrPipelineNotStarted = errors.New("pipeline not started")
+
+// ErrorPolicy what to do when a job in pipeline failed
+type ErrorPolicy int
+
+const (
+	// ErrorContinue execute all jobs can still execute by their trigger rules
+	ErrorContinue ErrorPolicy = iota
+	// ErrorFailFast cancle the running and pendding jobs on first failure
+	ErrorFailFast
+)
+
+func (e ErrorPolicy) String() string {
+	if e == ErrorFailFast {
+		return "fail_fast"
+	}
+	return "continue"
+}
+
 // Pipeline pipeline define
 type Pipeline struct {
 	Name       string
 	Jobs       []*Job
 	UniqueJobs []*Job
+	// track the in flight jobs, done is closed when all finished
+	tracker *runTracker
+	done    chan struct{}
+	onDone  func()
+	cancled bool
+	// what to do when a job failed
+	errorPolicy ErrorPolicy
+	// whether the run failed fast
+	failed bool
+	// the id of the run, empty if not started by RunPipeline
+	runID string
+	// the number of runs started
+	runs uint64
+	// the number of unresolved upstreams of each job
+	joins map[*Job]int
+	// the jobs triggered or decided not to execute
+	resolved  map[*Job]bool
+	startTime time.Time
+	endTime   time.Time
+	m         sync.Mutex
+}
+
+// JobResult the final state of a job in pipeline
+type JobResult struct {
+	Name   string
+	Status int
+	Result interface{}
+	Err    error
+	// whether the job triggered, a skipped job is not triggered
+	Trigged bool
+	// the failure of the job don't fail the pipeline
+	AllowFailure bool
+	// the job success in the run resumed from, not executed again
+	Cached    bool
+	Attempts  int
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// PipelineResult the result of a finished pipeline
+type PipelineResult struct {
+	Name  string
+	RunID string
+	// PipelineSuccess, PipelineFailed or PipelineCancled
+	Status    int
+	Jobs      []JobResult
+	StartTime time.Time
+	EndTime   time.Time
 }
 
 // NewPipeline get a new pipeline
@@ -22,11 +103,215 @@ func NewPipeline(name string, jobs ...*Job) (*Pipeline, error) {
 	return pipeline.new()
 }
 
-// Cancle cancle jobs to execute
+// WithErrorPolicy set what to do when a job failed, default is ErrorContinue,
+// the failure of jobs allowed to fail never fail fast
+func (p *Pipeline) WithErrorPolicy(policy ErrorPolicy) *Pipeline {
+	p.errorPolicy = policy
+	return p
+}
+
+// Cancle cancle jobs to execute, the context
+// of the running jobs will be cancelled
 func (p *Pipeline) Cancle() {
+	p.m.Lock()
+	p.cancled = true
+	// the jobs may be spawned while cancle
+	jobs := append([]*Job{}, p.UniqueJobs...)
+	p.m.Unlock()
+	for _, job := range jobs {
+		job.Cancle()
+	}
+}
+
+// Wait wait all triggered jobs of the pipeline finished,
+// return the context error if the context done first
+func (p *Pipeline) Wait(ctx context.Context) (*PipelineResult, error) {
+	p.m.Lock()
+	done := p.done
+	p.m.Unlock()
+	if done == nil {
+		return nil, ErrPipelineNotStarted
+	}
+	select {
+	case <-done:
+		return p.result(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// start prepare to track the jobs, the jobs added into a running
+// pipeline join the current run
+func (p *Pipeline) start() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.tracker != nil {
+		select {
+		case <-p.done:
+		default:
+			return
+		}
+	}
+	p.done = make(chan struct{})
+	p.failed = false
+	p.startTime = time.Now()
+	p.endTime = time.Time{}
+	done := p.done
+	p.tracker = &runTracker{done: func() {
+		p.m.Lock()
+		p.endTime = time.Now()
+		onDone := p.onDone
+		close(done)
+		p.m.Unlock()
+		if onDone != nil {
+			onDone()
+		}
+	}}
+	p.joins = make(map[*Job]int)
+	p.resolved = make(map[*Job]bool)
+	for _, job := range p.UniqueJobs {
+		job.tracker = p.tracker
+		job.pipeline = p
+		p.joins[job] = 0
+	}
 	for _, job := range p.UniqueJobs {
-		job.setStatus(JobCancled)
+		for _, children := range job.childrens {
+			if _, ok := p.joins[children]; ok {
+				p.joins[children]++
+			}
+		}
+		if len(job.parents) == 0 {
+			p.resolved[job] = true
+		}
+	}
+}
+
+// nextJobs resolve the downstreams of the finished job, return the jobs
+// should be triggered and the jobs skipped, each job is resolved once when
+// all it's upstreams resolved, the skipped jobs resolve their downstreams too
+func (p *Pipeline) nextJobs(job *Job) ([]*Job, []*Job) {
+	if p.failFast(job) {
+		return nil, nil
+	}
+	var (
+		next     []*Job
+		skipped  []*Job
+		resolved = []*Job{job}
+	)
+	for len(resolved) > 0 {
+		job, resolved = resolved[0], resolved[1:]
+		for _, children := range job.childrens {
+			p.m.Lock()
+			if _, ok := p.joins[children]; !ok || p.resolved[children] {
+				p.m.Unlock()
+				continue
+			}
+			p.joins[children]--
+			// the upstreams may be changed by the spawned jobs
+			decided, run := children.triggerRule.evaluate(children.parents, p.joins[children] == 0)
+			p.m.Unlock()
+
+			if !decided || !p.setResolved(children) {
+				continue
+			}
+			if run && (children.when == nil || children.when(children)) {
+				next = append(next, children)
+				continue
+			}
+			if children.skip() {
+				skipped = append(skipped, children)
+			}
+			resolved = append(resolved, children)
+		}
+	}
+	return next, skipped
+}
+
+// failFast cancle the other jobs when the job failed and the error
+// policy is fail fast, return true if the run already failed fast
+func (p *Pipeline) failFast(job *Job) bool {
+	p.m.Lock()
+	if p.failed {
+		p.m.Unlock()
+		return true
+	}
+	switch job.GetStatus() {
+	case JobFail, JobTimeout:
+	default:
+		p.m.Unlock()
+		return false
+	}
+	if p.errorPolicy != ErrorFailFast || job.IsAllowFailure() {
+		p.m.Unlock()
+		return false
+	}
+	p.failed = true
+	jobs := append([]*Job{}, p.UniqueJobs...)
+	p.m.Unlock()
+	for _, other := range jobs {
+		if other != job {
+			other.Cancle()
+		}
+	}
+	return true
+}
+
+// setResolved return false if the job already resolved
+func (p *Pipeline) setResolved(job *Job) bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.resolved[job] {
+		return false
+	}
+	p.resolved[job] = true
+	return true
+}
+
+func (p *Pipeline) result() *PipelineResult {
+	p.m.Lock()
+	result := &PipelineResult{
+		Name:      p.Name,
+		RunID:     p.runID,
+		Status:    PipelineSuccess,
+		StartTime: p.startTime,
+		EndTime:   p.endTime,
+	}
+	if p.cancled {
+		result.Status = PipelineCancled
+	}
+	// the jobs cancled by fail fast don't make the pipeline cancled
+	failed := p.failed && !p.cancled
+	if failed {
+		result.Status = PipelineFailed
+	}
+	jobs := append([]*Job{}, p.UniqueJobs...)
+	p.m.Unlock()
+
+	for _, job := range jobs {
+		jobResult := JobResult{
+			Name:         job.Name,
+			Status:       job.GetStatus(),
+			Trigged:      job.IsTrigged(),
+			AllowFailure: job.IsAllowFailure(),
+			Cached:       job.isCached(),
+			Attempts:     job.Attempts(),
+			StartTime:    job.GetStartTime(),
+			EndTime:      job.GetEndTime(),
+		}
+		jobResult.Result, jobResult.Err = job.GetResult()
+		switch jobResult.Status {
+		case JobFail, JobTimeout:
+			if result.Status == PipelineSuccess && !jobResult.AllowFailure {
+				result.Status = PipelineFailed
+			}
+		case JobCancled:
+			if !failed {
+				result.Status = PipelineCancled
+			}
+		}
+		result.Jobs = append(result.Jobs, jobResult)
 	}
+	return result
 }
 
 func (p *Pipeline) new() (*Pipeline, error) {
@@ -41,6 +326,50 @@ func (p *Pipeline) new() (*Pipeline, error) {
 	return p, nil
 }
 
+// clone copy the pipeline with new jobs which has no execute state,
+// so that the same define can be executed many times
+func (p *Pipeline) clone() *Pipeline {
+	pipeline, _ := p.cloneJobs(nil)
+	return pipeline
+}
+
+// cloneJobs copy the pipeline like clone without the dropped jobs,
+// return the copies of the jobs
+func (p *Pipeline) cloneJobs(dropped map[*Job]bool) (*Pipeline, map[*Job]*Job) {
+	var (
+		clones   = make(map[*Job]*Job)
+		pipeline = &Pipeline{Name: p.Name, errorPolicy: p.errorPolicy}
+	)
+	cloneJob := func(job *Job) *Job {
+		if _, ok := clones[job]; !ok {
+			clones[job] = job.clone()
+		}
+		return clones[job]
+	}
+	for _, job := range p.UniqueJobs {
+		if !dropped[job] {
+			pipeline.UniqueJobs = append(pipeline.UniqueJobs, cloneJob(job))
+		}
+	}
+	for _, job := range p.Jobs {
+		pipeline.Jobs = append(pipeline.Jobs, cloneJob(job))
+	}
+	for job, clone := range clones {
+		clone.spawner = clones[job.spawner]
+		for _, parent := range job.parents {
+			if parentClone, ok := clones[parent]; ok {
+				clone.parents = append(clone.parents, parentClone)
+			}
+		}
+		for _, children := range job.childrens {
+			if childrenClone, ok := clones[children]; ok {
+				clone.childrens = append(clone.childrens, childrenClone)
+			}
+		}
+	}
+	return pipeline, clones
+}
+
 // isCycleAdded whether cycle added
 func (p *Pipeline) isCycleAdded(topJobs []*Job) error {
 	var (
@@ -127,6 +456,11 @@ func (p *Pipeline) Graph() (string, error) {
 
 func (p *Pipeline) graph(job *Job) string {
 	lines := job.Name
+	if job.sub != nil {
+		sub, _ := job.sub.Graph()
+		lines += fmt.Sprintf("{%s}",
+			strings.ReplaceAll(strings.TrimSuffix(sub, "\n"), "\n", "; "))
+	}
 	if len(job.GetDownstreams()) != 0 {
 		for _, children := range job.GetDownstreams() {
 			lines += fmt.Sprintf(" -> %s", p.graph(children))
@@ -1,6 +1,9 @@
 package gopool
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -75,3 +78,273 @@ func TestPipeline(t *testing.T) {
 	pool.AddPipeline(pipeline)
 	time.Sleep(time.Second)
 }
+
+func TestPipelineCancle(t *testing.T) {
+	p := NewPool(10, 2)
+	job1 := NewContextJob("job1", &contextJob{})
+	job2 := NewJob("job2", &testJob{Name: "job2"})
+	if err := job2.After(job1); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	pipeline, err := NewPipeline("cancle", job1, job2)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if err := p.AddPipeline(pipeline); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if !waitStatus(job1, JobRunning, time.Second) {
+		t.Error("job1 not running")
+		t.FailNow()
+	}
+	pipeline.Cancle()
+	if !waitStatus(job1, JobCancled, time.Second) {
+		t.Errorf("job1 status %d, want cancled", job1.GetStatus())
+	}
+	if job2.GetStatus() != JobCancled {
+		t.Errorf("job2 status %d, want cancled", job2.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestPipelineCancleJob(t *testing.T) {
+	p := NewPool(10, 2)
+	release := make(chan struct{})
+	a := NewFuncJob("a", func() (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	b := NewJob("b", &testJob{})
+	c := NewJob("c", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	d := NewJob("d", &testJob{})
+	b.After(a)
+	c.After(b)
+	d.After(b)
+	pipeline, _ := NewPipeline("cancle", a, b, c, d)
+	p.AddPipeline(pipeline)
+	b.Cancle()
+	close(release)
+	if _, err := pipeline.Wait(context.Background()); err != nil {
+		t.Error(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := NewFuture(c).WaitContext(ctx); err == context.DeadlineExceeded {
+		t.Error("wait the downstream of cancled job timeout")
+	}
+	if b.GetStatus() != JobCancled || c.GetStatus() != JobSkipped || d.GetStatus() != JobSuccess {
+		t.Errorf("unexpected status b %d c %d d %d", b.GetStatus(), c.GetStatus(), d.GetStatus())
+	}
+	p.Close("finish")
+}
+
+type failJob struct{}
+
+func (j *failJob) Handle() (interface{}, error) {
+	return nil, errors.New("fail")
+}
+
+func TestPipelineWait(t *testing.T) {
+	p := NewPool(10, 2)
+	job1 := NewJob("job1", &resultJob{result: "job1"})
+	job2 := NewJob("job2", &resultJob{result: "job2", d: 10 * time.Millisecond})
+	job3 := NewJob("job3", &testJob{}).When(func(self *Job) bool { return false })
+	job2.After(job1)
+	job3.After(job1)
+	pipeline, err := NewPipeline("wait", job1, job2, job3)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if _, err := pipeline.Wait(context.Background()); err != ErrPipelineNotStarted {
+		t.Errorf("unexpected error %v", err)
+	}
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if result.Status != PipelineSuccess || len(result.Jobs) != 3 {
+		t.Errorf("unexpected result %+v", result)
+		t.FailNow()
+	}
+	for _, job := range result.Jobs {
+		t.Logf("%+v", job)
+		switch job.Name {
+		case "job2":
+			if job.Status != JobSuccess || job.Result != "job2" || job.EndTime.Before(job.StartTime) {
+				t.Errorf("unexpected job result %+v", job)
+			}
+		case "job3":
+			if job.Status != JobSkipped || job.Trigged {
+				t.Errorf("unexpected job result %+v", job)
+			}
+		}
+	}
+	p.Close("finish")
+}
+
+func TestPipelineWaitFailed(t *testing.T) {
+	p := NewPool(10, 2)
+	job1 := NewJob("job1", &failJob{})
+	job2 := NewJob("job2", &testJob{})
+	job2.After(job1)
+	pipeline, _ := NewPipeline("fail", job1, job2)
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineFailed {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+
+	block := NewContextJob("block", &contextJob{})
+	pipeline, _ = NewPipeline("cancle", block)
+	p.AddPipeline(pipeline)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pipeline.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error %v", err)
+	}
+	pipeline.Cancle()
+	result, err = pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineCancled {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	p.Close("finish")
+}
+
+func TestPipelineJoin(t *testing.T) {
+	var count int32
+	p := NewPool(10, 3)
+	root := NewJob("root", &testJob{})
+	parents := []*Job{
+		NewJob("parent1", &resultJob{d: 10 * time.Millisecond}),
+		NewJob("parent2", &resultJob{d: 30 * time.Millisecond}),
+		NewJob("parent3", &panicJob{}),
+	}
+	join := NewJob("join", &testJob{}).WithOnce().WithResultCallback(func(result interface{}, err error) {
+		atomic.AddInt32(&count, 1)
+	})
+	root.Before(parents...)
+	join.After(parents...)
+	pipeline, err := NewPipeline("join", root, join)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	p.AddPipeline(pipeline)
+	if _, err := pipeline.Wait(context.Background()); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if atomic.LoadInt32(&count) != 1 {
+		t.Errorf("join executed %d times", count)
+	}
+	for _, parent := range parents {
+		if join.GetStartTime().Before(parent.GetEndTime()) {
+			t.Errorf("join started before %s finished", parent)
+		}
+	}
+	p.Close("finish")
+}
+
+func TestPipelineJoinNotTrigged(t *testing.T) {
+	p := NewPool(10, 3)
+	job1 := NewJob("job1", &testJob{})
+	job2 := NewJob("job2", &testJob{}).When(func(self *Job) bool { return false })
+	job3 := NewJob("job3", &testJob{})
+	job4 := NewJob("job4", &testJob{})
+	job2.After(job1)
+	job3.After(job1, job2)
+	job4.After(job2)
+	pipeline, _ := NewPipeline("join", job1, job2, job3, job4)
+	p.AddPipeline(pipeline)
+	if _, err := pipeline.Wait(context.Background()); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if job2.GetStatus() != JobSkipped || job3.GetStatus() != JobSuccess || job4.GetStatus() != JobSkipped {
+		t.Errorf("job2 status %d job3 status %d job4 status %d",
+			job2.GetStatus(), job3.GetStatus(), job4.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestPipelineSkipped(t *testing.T) {
+	p := NewPool(10, 3)
+	job1 := NewJob("job1", &failJob{})
+	job2 := NewJob("job2", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	job3 := NewJob("job3", &testJob{})
+	job4 := NewJob("job4", &testJob{}).WithTriggerRule(TriggerAllDone)
+	job2.After(job1)
+	job3.After(job2)
+	job4.After(job2)
+	pipeline, _ := NewPipeline("skip", job1, job2, job3, job4)
+	future := NewFuture(job3)
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineFailed {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	for _, job := range result.Jobs {
+		switch job.Name {
+		case "job2", "job3":
+			if job.Status != JobSkipped || job.Trigged {
+				t.Errorf("unexpected job result %+v", job)
+			}
+		case "job4":
+			if job.Status != JobSuccess {
+				t.Errorf("unexpected job result %+v", job)
+			}
+		}
+	}
+	if _, err := future.Result(); err != ErrJobSkipped {
+		t.Errorf("unexpected error %v", err)
+	}
+	p.Close("finish")
+}
+
+func TestPipelineFailFast(t *testing.T) {
+	p := NewPool(10, 3)
+	fail := NewJob("fail", &failJob{})
+	block := NewContextJob("block", &contextJob{})
+	next := NewJob("next", &testJob{})
+	root := NewJob("root", &testJob{})
+	root.Before(fail, block)
+	next.After(fail)
+	pipeline, _ := NewPipeline("fail fast", root)
+	pipeline.WithErrorPolicy(ErrorFailFast)
+	p.AddPipeline(pipeline)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := pipeline.Wait(ctx)
+	if err != nil || result.Status != PipelineFailed {
+		t.Errorf("unexpected result %+v %v", result, err)
+		t.FailNow()
+	}
+	if block.GetStatus() != JobCancled || next.GetStatus() != JobCancled {
+		t.Errorf("block status %d next status %d", block.GetStatus(), next.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestPipelineAllowFailure(t *testing.T) {
+	p := NewPool(10, 3)
+	fail := NewJob("fail", &failJob{}).AllowFailure()
+	next := NewJob("next", &testJob{}).WithTriggerRule(TriggerAllDone)
+	next.After(fail)
+	pipeline, _ := NewPipeline("allow failure", fail)
+	pipeline.WithErrorPolicy(ErrorFailFast)
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineSuccess {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	if fail.GetStatus() != JobFail || next.GetStatus() != JobSuccess {
+		t.Errorf("fail status %d next status %d", fail.GetStatus(), next.GetStatus())
+	}
+	p.Close("finish")
+}
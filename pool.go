@@ -1,6 +1,7 @@
 package gopool
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -30,8 +31,30 @@ var (
 	ErrPoolExit = errors.New("Pool exit")
 	// ErrPoolPanic run got panic
 	ErrPoolPanic = errors.New("panic")
+	// ErrPoolFull the pool queue is full
+	ErrPoolFull = errors.New("Pool full")
 )
 
+// AddJobError some jobs of the batch not added into pool
+type AddJobError struct {
+	// the reason why the jobs not added
+	Err error
+	// the jobs already added into pool
+	Added []*Job
+	// the jobs not added into pool
+	Rejected []*Job
+}
+
+func (e *AddJobError) Error() string {
+	return fmt.Sprintf("%d jobs added, %d jobs rejected: %s",
+		len(e.Added), len(e.Rejected), e.Err.Error())
+}
+
+// Unwrap get the reason
+func (e *AddJobError) Unwrap() error {
+	return e.Err
+}
+
 // Pool job pool
 type Pool struct {
 	capacity uint64
@@ -40,8 +63,17 @@ type Pool struct {
 	// the number of running goroutine
 	workers uint64
 	// the number of running jobs
-	runners       uint64
-	jobs          chan *Job
+	runners uint64
+	// the number of jobs wait to retry
+	retries uint64
+	jobs    *jobQueue
+	// delayed jobs wait to be added into queue
+	timers *timerWheel
+	// what to do when the queue is full
+	rejectPolicy  RejectPolicy
+	rejectHandler RejectHandler
+	// the number of rejected jobs
+	rejected      uint64
 	exitCallback  func(reason string)
 	panicCallback func(r interface{})
 	eventCallback func(event *Event)
@@ -49,6 +81,9 @@ type Pool struct {
 	status        int
 	liveTime      time.Duration
 	m             sync.RWMutex
+	// the parent context of all jobs, cancelled when pool close
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewPool get a new job pool
@@ -63,12 +98,16 @@ func NewPool(capacity, maxActive uint64) *Pool {
 	if maxActive == 0 {
 		maxActive = capacity / 2
 	}
+	ctx, cancel := context.WithCancel(context.Background())
 	pool := &Pool{
 		maxActive: maxActive,
-		jobs:      make(chan *Job, capacity),
+		jobs:      newJobQueue(capacity),
 		status:    PoolRunning,
 		liveTime:  time.Minute,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+	pool.timers = newTimerWheel(DEFAULT_TIMER_TICK, DEFAULT_TIMER_SLOTS, pool.addScheduledJob)
 	pool.increaseWorker()
 	return pool
 }
@@ -93,30 +132,119 @@ func (p *Pool) WithEventCallback(level EventLevel, handle func(event *Event)) *P
 	return p
 }
 
-// AddPipeline add a new pipeline into pool
+// WithPriorityQueue execute jobs by priority instead of added order,
+// the priority of waiting job increase 1 after every aging duration
+// to prevent starvation, no aging if zero
+func (p *Pool) WithPriorityQueue(aging time.Duration) *Pool {
+	p.jobs.setStore(newPriorityStore(aging))
+	return p
+}
+
+// AddPipeline add a new pipeline into pool,
+// use Pipeline.Wait to wait the pipeline finished,
+// the jobs keep the state of the run, use RunPipeline
+// to run the same pipeline many times
 func (p *Pool) AddPipeline(pipeline *Pipeline) error {
 	topJobs, err := pipeline.getTopJobs()
 	if err != nil {
 		return err
 	}
+	pipeline.start()
+	// keep the pipeline running until all top jobs added
+	pipeline.tracker.add()
+	defer pipeline.tracker.finish()
 	return p.AddJob(topJobs...)
 }
 
-// AddJob add a new job into pipeline
+// AddJob add a new job into pipeline, block when
+// the pool is full unless other reject policy set
 func (p *Pool) AddJob(jobs ...*Job) error {
+	return p.addJobs(jobs, p.put)
+}
+
+// TryAddJob add jobs into pool without block, return *AddJobError
+// wrap ErrPoolFull with the added jobs when the pool is full
+func (p *Pool) TryAddJob(jobs ...*Job) error {
+	return p.addJobs(jobs, func(job *Job) error {
+		if !p.jobs.tryPut(job) {
+			return ErrPoolFull
+		}
+		return nil
+	})
+}
+
+// AddJobContext add jobs into pool, return *AddJobError wrap
+// the context error with the added jobs when the context done
+func (p *Pool) AddJobContext(ctx context.Context, jobs ...*Job) error {
+	return p.addJobs(jobs, func(job *Job) error {
+		return p.jobs.putContext(ctx, job)
+	})
+}
+
+// addJobs add jobs by put one by one, stop at the first fail
+func (p *Pool) addJobs(jobs []*Job, put func(job *Job) error) error {
 	status := p.getStatus()
 	if status == PoolExiting || status == PoolExited {
 		return ErrPoolExit
 	}
-	for _, job := range jobs {
+	var added []*Job
+	for index, job := range jobs {
 		p.sendEvent(EventLevelDebug, fmt.Sprintf("add job '%s' into queue", job.Name))
-		if job.setTrigged() {
-			p.jobs <- job
+		if !job.setTrigged() {
+			continue
 		}
+		job.tracker.add()
+		if err := put(job); err != nil {
+			job.resetTrigged()
+			job.tracker.finish()
+			return &AddJobError{Err: err, Added: added, Rejected: jobs[index:]}
+		}
+		added = append(added, job)
 	}
 	return nil
 }
 
+// AddJobAt add the job into pool at the time
+func (p *Pool) AddJobAt(t time.Time, job *Job) error {
+	return p.AddJobAfter(time.Until(t), job)
+}
+
+// AddJobAfter add the job into pool after the delay,
+// the job not added when pool close will be cancled
+func (p *Pool) AddJobAfter(delay time.Duration, job *Job) error {
+	status := p.getStatus()
+	if status == PoolExiting || status == PoolExited {
+		return ErrPoolExit
+	}
+	p.sendEvent(EventLevelDebug,
+		fmt.Sprintf("schedule job '%s' after %s", job.Name, delay))
+	return p.timers.add(delay, job)
+}
+
+// CancleScheduled cancle the scheduled job before it's added into queue,
+// return false if the job not scheduled or already added
+func (p *Pool) CancleScheduled(job *Job) bool {
+	if !p.timers.remove(job) {
+		return false
+	}
+	job.Cancle()
+	p.sendEvent(EventLevelDebug,
+		fmt.Sprintf("scheduled job '%s' cancled", job.Name))
+	return true
+}
+
+// ScheduledJobs get the number of jobs wait for their scheduled time
+func (p *Pool) ScheduledJobs() int {
+	return p.timers.len()
+}
+
+func (p *Pool) addScheduledJob(job *Job) {
+	if err := p.AddJob(job); err != nil {
+		p.sendEvent(EventLevelError,
+			fmt.Sprintf("add scheduled job '%s' fail[%s]", job.Name, err.Error()))
+	}
+}
+
 // Status get pool status
 func (p *Pool) Status() int {
 	p.m.RLock()
@@ -131,7 +259,12 @@ func (p *Pool) RunningJobs() uint64 {
 
 // PenddingJobs get pendding jobs number
 func (p *Pool) PenddingJobs() int {
-	return len(p.jobs)
+	return p.jobs.len()
+}
+
+// RetryingJobs get the number of jobs wait to retry
+func (p *Pool) RetryingJobs() uint64 {
+	return atomic.LoadUint64(&p.retries)
 }
 
 // Workers get running goroutine number
@@ -139,7 +272,8 @@ func (p *Pool) Workers() uint64 {
 	return atomic.LoadUint64(&p.workers)
 }
 
-// Close close the pool
+// Close close the pool, the context of context aware
+// jobs will be cancelled, pendding jobs still be processed
 func (p *Pool) Close(reason string) error {
 	status := p.Status()
 	if status == PoolExited || status == PoolExiting {
@@ -147,10 +281,18 @@ func (p *Pool) Close(reason string) error {
 	}
 	// set pool status to exiting
 	p.setStatus(PoolExiting)
+	// notify all context aware jobs to stop
+	p.cancel()
+	// cancle the jobs not reach their scheduled time
+	for _, job := range p.timers.close() {
+		job.Cancle()
+		p.sendEvent(EventLevelInfo,
+			fmt.Sprintf("scheduled job '%s' cancled because pool exit", job.Name))
+	}
 	// wait all job finish
 	p.waitAllJobFinish()
-	// close job channel
-	close(p.jobs)
+	// close job queue
+	p.jobs.close()
 	// wait all worker exit
 	p.waitAllWorkerExit()
 	if p.exitCallback != nil {
@@ -165,10 +307,11 @@ func (p *Pool) waitAllJobFinish() {
 	for {
 		runningJobs := p.RunningJobs()
 		penddingJobs := p.PenddingJobs()
+		retryingJobs := p.RetryingJobs()
 		p.sendEvent(EventLevelInfo,
-			fmt.Sprintf("wait all job finish, running=%d, pendding=%d",
-				runningJobs, penddingJobs))
-		if runningJobs == 0 && penddingJobs == 0 {
+			fmt.Sprintf("wait all job finish, running=%d, pendding=%d, retrying=%d",
+				runningJobs, penddingJobs, retryingJobs))
+		if runningJobs == 0 && penddingJobs == 0 && retryingJobs == 0 {
 			return
 		}
 		time.Sleep(10 * time.Millisecond)
@@ -231,25 +374,9 @@ func (p *Pool) decreaseRunner(job *Job) {
 }
 
 func (p *Pool) startWorker(workerNum uint64) {
-	var currentJob *Job
-
 	ticker := time.NewTicker(p.liveTime)
 	defer ticker.Stop()
 
-	defer func() {
-		if r := recover(); r != nil {
-			p.sendEvent(EventLevelError,
-				fmt.Sprintf("worker '%d' execute job '%s' panic %v, stack: %s",
-					workerNum, currentJob, r, debug.Stack()))
-			p.decreaseRunner(currentJob)
-			p.decreaseWorker(workerNum)
-			currentJob.setResult(nil, fmt.Errorf("%s panic", currentJob.Name))
-			p.increaseWorker()
-			if p.panicCallback != nil {
-				p.panicCallback(r)
-			}
-		}
-	}()
 	for {
 		select {
 		case <-ticker.C:
@@ -258,39 +385,191 @@ func (p *Pool) startWorker(workerNum uint64) {
 				return
 			}
 			ticker.Reset(p.liveTime)
-		case job, ok := <-p.jobs:
+		case _, ok := <-p.jobs.tokens:
 			ticker.Reset(p.liveTime)
 			if !ok {
 				p.decreaseWorker(workerNum)
 				return
 			}
-			if job.GetStatus() == JobCancled {
-				continue
-			}
-			currentJob = job
-			p.increaseRunner(job)
+			p.runJob(fmt.Sprintf("worker '%d'", workerNum), p.jobs.take())
 
-			job.setStatus(JobRunning)
+			if p.Workers() < p.maxActive && p.PenddingJobs() > int(p.capacity/2) {
+				p.increaseWorker()
+			}
+		}
+	}
+}
 
-			job.setResult(job.handler.Handle())
+// runJob execute the job and add it's next jobs into pool,
+// runner is the name of the goroutine execute the job
+func (p *Pool) runJob(runner string, job *Job) {
+	ctx, ok := job.start(p.ctx)
+	if !ok {
+		p.sendEvent(EventLevelDebug,
+			fmt.Sprintf("job '%s' cancled, skip", job))
+		p.cancleJob(job)
+		return
+	}
+	p.increaseRunner(job)
+	if job.sub != nil {
+		p.runPipelineJob(ctx, job)
+		return
+	}
 
+	defer func() {
+		if r := recover(); r != nil {
+			p.sendEvent(EventLevelError,
+				fmt.Sprintf("%s execute job '%s' panic %v, stack: %s",
+					runner, job, r, debug.Stack()))
 			p.decreaseRunner(job)
-
-			nextJobs := job.getNextExecuteJobs()
-			p.sendEvent(EventLevelDebug,
-				fmt.Sprintf("add next jobs %v , running=%d, pendding=%d, workers=%d",
-					nextJobs, p.RunningJobs(), p.PenddingJobs(), p.Workers()))
-			if err := p.AddJob(nextJobs...); err != nil {
-				p.sendEvent(EventLevelError,
-					fmt.Sprintf("add next jobs %v fail[%s], running=%d, pendding=%d, workers=%d",
-						nextJobs, err.Error(), p.RunningJobs(), p.PenddingJobs(), p.Workers()))
+			job.setResult(nil, fmt.Errorf("%s %w", job.Name, ErrPoolPanic))
+			p.addNextJobs(job)
+			job.tracker.finish()
+			if p.panicCallback != nil {
+				p.panicCallback(r)
 			}
+		}
+	}()
 
-			if p.Workers() < p.maxActive && p.PenddingJobs() > int(p.capacity/2) {
-				p.increaseWorker()
-			}
+	result, err := p.execute(ctx, job)
+	p.finishJob(job, result, err)
+}
+
+// finishJob retry the failed job or set the result of the job
+// and add it's next jobs into pool
+func (p *Pool) finishJob(job *Job, result interface{}, err error) {
+	if delay, ok := job.prepareRetry(err); ok {
+		p.retryJob(job, delay, err)
+		p.decreaseRunner(job)
+		return
+	}
+	if err != nil && job.retry != nil {
+		p.sendEvent(EventLevelError,
+			fmt.Sprintf("job '%s' fail[%s] after %d attempts",
+				job, err.Error(), job.Attempts()))
+	}
+	job.setResult(result, err)
+
+	p.decreaseRunner(job)
+
+	p.addNextJobs(job)
+	job.tracker.finish()
+}
+
+// addNextJobs add the downstreams should be triggered into pool
+func (p *Pool) addNextJobs(job *Job) {
+	nextJobs, skippedJobs := job.getNextExecuteJobs()
+	for _, skipped := range skippedJobs {
+		p.sendEvent(EventLevelInfo,
+			fmt.Sprintf("job '%s' skipped by trigger rule '%s' or condition",
+				skipped, skipped.triggerRule))
+	}
+	p.sendEvent(EventLevelDebug,
+		fmt.Sprintf("add next jobs %v , running=%d, pendding=%d, workers=%d",
+			nextJobs, p.RunningJobs(), p.PenddingJobs(), p.Workers()))
+	if err := p.addPipelineJobs(nextJobs); err != nil {
+		p.sendEvent(EventLevelError,
+			fmt.Sprintf("add next jobs %v fail[%s], running=%d, pendding=%d, workers=%d",
+				nextJobs, err.Error(), p.RunningJobs(), p.PenddingJobs(), p.Workers()))
+	}
+}
+
+// cancleJob finish the job cancled before executed, the downstreams in
+// pipeline are resolved by their trigger rules
+func (p *Pool) cancleJob(job *Job) {
+	if job.pipeline != nil {
+		p.addNextJobs(job)
+	}
+	job.tracker.finish()
+}
+
+// retryJob add the job into pool again after delay
+func (p *Pool) retryJob(job *Job, delay time.Duration, err error) {
+	retries := atomic.AddUint64(&p.retries, 1)
+	p.sendEvent(EventLevelWarring,
+		fmt.Sprintf("job '%s' attempt %d fail[%s], retry after %s, retrying=%d",
+			job, job.Attempts(), err.Error(), delay, retries))
+	time.AfterFunc(delay, func() {
+		defer atomic.AddUint64(&p.retries, ^uint64(0))
+		// the pool closed during the backoff, finish with the last error
+		if p.ctx.Err() != nil {
+			p.sendEvent(EventLevelError,
+				fmt.Sprintf("job '%s' not retried because pool exit", job))
+			job.setResult(nil, err)
+			p.addNextJobs(job)
+			job.tracker.finish()
+			return
 		}
+		p.jobs.put(job)
+	})
+}
+
+// runTracker track the in flight jobs of one run,
+// done is called when all triggered jobs finished
+type runTracker struct {
+	inflight int64
+	done     func()
+}
+
+// add a job of the run is added into queue
+func (t *runTracker) add() {
+	if t != nil {
+		atomic.AddInt64(&t.inflight, 1)
+	}
+}
+
+// finish a job of the run finished and it's next jobs added
+func (t *runTracker) finish() {
+	if t != nil && atomic.AddInt64(&t.inflight, -1) == 0 && t.done != nil {
+		t.done()
+	}
+}
+
+// handleResult the result of the handler running in other goroutine
+type handleResult struct {
+	result interface{}
+	err    error
+	// the recovered panic
+	panic interface{}
+}
+
+// execute run the job handler, a job with timeout run it's handler in
+// a new goroutine so that the worker is released when timeout
+func (p *Pool) execute(ctx context.Context, job *Job) (interface{}, error) {
+	if job.timeout <= 0 {
+		return job.handler.Handle(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+	done := make(chan handleResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- handleResult{panic: r}
+			}
+		}()
+		result, err := job.handler.Handle(ctx)
+		done <- handleResult{result: result, err: err}
+	}()
+
+	timer := time.NewTimer(job.timeout)
+	defer timer.Stop()
+
+	var r handleResult
+	select {
+	case r = <-done:
+	case <-timer.C:
+		p.sendEvent(EventLevelWarring,
+			fmt.Sprintf("job '%s' timeout after %s", job, job.timeout))
+		return nil, ErrJobTimeout
+	}
+	if r.panic != nil {
+		panic(r.panic)
+	}
+	if r.err != nil && ctx.Err() == context.DeadlineExceeded {
+		return r.result, ErrJobTimeout
 	}
+	return r.result, r.err
 }
 
 func (p *Pool) sendEvent(level EventLevel, msg string) {
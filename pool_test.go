@@ -1,6 +1,8 @@
 package gopool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 	"testing"
@@ -94,10 +96,72 @@ func TestPoolManyTrigged(t *testing.T) {
 
 	time.Sleep(2 * time.Second)
 	job3TriggedCount := atomic.LoadInt32(&count)
-	if job3TriggedCount != 1 && job3TriggedCount != 2 {
+	if job3TriggedCount != 1 {
 		t.Error("not execute")
 		t.FailNow()
 	}
 	t.Logf("job3 trigged count %d", job3TriggedCount)
 	p.Close("finish")
 }
+
+func TestPoolCloseCancleJobs(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewContextJob("contextJob", &contextJob{})
+	p.AddJob(job)
+	if !waitStatus(job, JobRunning, time.Second) {
+		t.Error("job not running")
+		t.FailNow()
+	}
+	p.Close("finish")
+	if job.GetStatus() != JobCancled {
+		t.Errorf("job status %d, want cancled", job.GetStatus())
+	}
+}
+
+func TestPoolTryAddJob(t *testing.T) {
+	p := NewPool(2, 1)
+	block := make(chan struct{})
+	running := NewJob("block", &funcJob{f: func() { <-block }})
+	p.AddJob(running)
+	waitStatus(running, JobRunning, time.Second)
+
+	job1 := NewJob("job1", &testJob{})
+	job2 := NewJob("job2", &testJob{})
+	job3 := NewJob("job3", &testJob{}).WithOnce()
+	err := p.TryAddJob(job1, job2, job3)
+	addErr, ok := err.(*AddJobError)
+	if !ok || !errors.Is(err, ErrPoolFull) {
+		t.Errorf("unexpected error %v", err)
+		t.FailNow()
+	}
+	if len(addErr.Added) != 2 || len(addErr.Rejected) != 1 || addErr.Rejected[0] != job3 {
+		t.Errorf("added %v rejected %v", addErr.Added, addErr.Rejected)
+	}
+	if job3.IsTrigged() {
+		t.Error("rejected job should not be trigged")
+	}
+	close(block)
+	p.Close("finish")
+}
+
+func TestPoolAddJobContext(t *testing.T) {
+	p := NewPool(1, 1)
+	block := make(chan struct{})
+	running := NewJob("block", &funcJob{f: func() { <-block }})
+	p.AddJob(running)
+	waitStatus(running, JobRunning, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.AddJobContext(ctx, NewJob("job1", &testJob{}), NewJob("job2", &testJob{}))
+	addErr, ok := err.(*AddJobError)
+	if !ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+		t.FailNow()
+	}
+	if len(addErr.Added) != 1 || len(addErr.Rejected) != 1 {
+		t.Errorf("added %v rejected %v", addErr.Added, addErr.Rejected)
+	}
+	close(block)
+	p.Close("finish")
+}
@@ -0,0 +1,222 @@
+package gopool
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// jobStore the storage of pendding jobs
+type jobStore interface {
+	push(job *Job)
+	pop() *Job
+	// remove the earliest added job
+	evict() *Job
+	len() int
+}
+
+// jobQueue pendding jobs queue, blocked when full,
+// workers receive a token before take a job
+type jobQueue struct {
+	store jobStore
+	// one slot for each job in queue, limit the queue capacity
+	slots chan struct{}
+	// one token for each job in store
+	tokens chan struct{}
+	m      sync.Mutex
+}
+
+func newJobQueue(capacity uint64) *jobQueue {
+	return &jobQueue{
+		store:  &fifoStore{},
+		slots:  make(chan struct{}, capacity),
+		tokens: make(chan struct{}, capacity),
+	}
+}
+
+// put add job into queue, block when the queue is full
+func (q *jobQueue) put(job *Job) {
+	q.slots <- struct{}{}
+	q.push(job)
+}
+
+// tryPut add job into queue, return false when the queue is full
+func (q *jobQueue) tryPut(job *Job) bool {
+	select {
+	case q.slots <- struct{}{}:
+	default:
+		return false
+	}
+	q.push(job)
+	return true
+}
+
+// putContext add job into queue, give up when the context done
+func (q *jobQueue) putContext(ctx context.Context, job *Job) error {
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	q.push(job)
+	return nil
+}
+
+func (q *jobQueue) push(job *Job) {
+	q.m.Lock()
+	q.store.push(job)
+	q.m.Unlock()
+	q.tokens <- struct{}{}
+}
+
+// take get a job from queue, must be called after received a token
+func (q *jobQueue) take() *Job {
+	q.m.Lock()
+	job := q.store.pop()
+	q.m.Unlock()
+	<-q.slots
+	return job
+}
+
+// evict remove the earliest added job from queue, return nil if empty
+func (q *jobQueue) evict() *Job {
+	select {
+	case _, ok := <-q.tokens:
+		if !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	q.m.Lock()
+	job := q.store.evict()
+	q.m.Unlock()
+	<-q.slots
+	return job
+}
+
+func (q *jobQueue) len() int {
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.store.len()
+}
+
+// setStore change the storage, move the pendding jobs into new storage
+func (q *jobQueue) setStore(store jobStore) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	for q.store.len() > 0 {
+		store.push(q.store.pop())
+	}
+	q.store = store
+}
+
+// close notify workers no more jobs
+func (q *jobQueue) close() {
+	close(q.tokens)
+}
+
+// fifoStore first in first out
+type fifoStore struct {
+	jobs []*Job
+}
+
+func (s *fifoStore) push(job *Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+func (s *fifoStore) pop() *Job {
+	job := s.jobs[0]
+	s.jobs[0] = nil
+	s.jobs = s.jobs[1:]
+	return job
+}
+
+func (s *fifoStore) evict() *Job {
+	return s.pop()
+}
+
+func (s *fifoStore) len() int {
+	return len(s.jobs)
+}
+
+// priorityItem job in priority heap
+type priorityItem struct {
+	job *Job
+	// the job priority increased by the waiting time
+	key float64
+	seq uint64
+}
+
+type priorityItems []*priorityItem
+
+func (items priorityItems) Len() int { return len(items) }
+
+func (items priorityItems) Less(i, j int) bool {
+	if items[i].key == items[j].key {
+		return items[i].seq < items[j].seq
+	}
+	return items[i].key > items[j].key
+}
+
+func (items priorityItems) Swap(i, j int) { items[i], items[j] = items[j], items[i] }
+
+func (items *priorityItems) Push(x interface{}) {
+	*items = append(*items, x.(*priorityItem))
+}
+
+func (items *priorityItems) Pop() interface{} {
+	old := *items
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*items = old[:len(old)-1]
+	return item
+}
+
+// priorityStore the job with higher priority pop first,
+// the jobs with same priority pop in added order
+type priorityStore struct {
+	items priorityItems
+	seq   uint64
+	// the priority of waiting job increase 1 after every aging,
+	// no aging if zero
+	aging time.Duration
+	base  time.Time
+}
+
+func newPriorityStore(aging time.Duration) *priorityStore {
+	return &priorityStore{
+		aging: aging,
+		base:  time.Now(),
+	}
+}
+
+// push the key is priority + waited/aging, as all jobs aging in same speed
+// it equals priority - added/aging which never change after added
+func (s *priorityStore) push(job *Job) {
+	key := float64(job.priority)
+	if s.aging > 0 {
+		key -= float64(time.Since(s.base)) / float64(s.aging)
+	}
+	s.seq++
+	heap.Push(&s.items, &priorityItem{job: job, key: key, seq: s.seq})
+}
+
+func (s *priorityStore) pop() *Job {
+	return heap.Pop(&s.items).(*priorityItem).job
+}
+
+func (s *priorityStore) evict() *Job {
+	oldest := 0
+	for i, item := range s.items {
+		if item.seq < s.items[oldest].seq {
+			oldest = i
+		}
+	}
+	return heap.Remove(&s.items, oldest).(*priorityItem).job
+}
+
+func (s *priorityStore) len() int {
+	return s.items.Len()
+}
@@ -0,0 +1,49 @@
+package gopool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityStore(t *testing.T) {
+	store := newPriorityStore(0)
+	for i, priority := range []int{1, 3, 2, 3} {
+		store.push(NewJob(string(rune('a'+i)), &testJob{}).WithPriority(priority))
+	}
+	order := ""
+	for store.len() > 0 {
+		order += store.pop().Name
+	}
+	if order != "bdca" {
+		t.Errorf("pop order %s, want bdca", order)
+	}
+}
+
+func TestPriorityStoreAging(t *testing.T) {
+	store := newPriorityStore(time.Millisecond)
+	store.push(NewJob("old", &testJob{}).WithPriority(0))
+	time.Sleep(10 * time.Millisecond)
+	store.push(NewJob("new", &testJob{}).WithPriority(5))
+	if job := store.pop(); job.Name != "old" {
+		t.Errorf("pop %s, want old", job.Name)
+	}
+}
+
+func TestPriorityPool(t *testing.T) {
+	var order []string
+	p := NewPool(10, 1).WithPriorityQueue(0)
+	block := make(chan struct{})
+	p.AddJob(NewJob("block", &funcJob{f: func() { <-block }}))
+	for i, priority := range []int{1, 3, 2} {
+		name := string(rune('a' + i))
+		p.AddJob(NewJob(name, &testJob{}).WithPriority(priority).
+			WithResultCallback(func(result interface{}, err error) {
+				order = append(order, name)
+			}))
+	}
+	close(block)
+	p.Close("finish")
+	if len(order) != 3 || order[0] != "b" || order[1] != "c" || order[2] != "a" {
+		t.Errorf("execute order %v, want [b c a]", order)
+	}
+}
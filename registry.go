@@ -0,0 +1,65 @@
+package gopool
+
+import (
+	"fmt"
+	"sync"
+)
+
+// HandlerFactory build the job handler by the params
+type HandlerFactory func(params map[string]interface{}) (JobHandler, error)
+
+// ContextHandlerFactory build the context job handler by the params
+type ContextHandlerFactory func(params map[string]interface{}) (ContextJobHandler, error)
+
+// Registry the handler factories by name, used to build
+// the jobs of the pipeline declared in JSON or YAML
+type Registry struct {
+	factories map[string]ContextHandlerFactory
+	m         sync.RWMutex
+}
+
+// NewRegistry get a new handler registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]ContextHandlerFactory)}
+}
+
+// Register register the handler factory by name
+func (r *Registry) Register(name string, factory HandlerFactory) error {
+	return r.RegisterContext(name, func(params map[string]interface{}) (ContextJobHandler, error) {
+		handler, err := factory(params)
+		if err != nil {
+			return nil, err
+		}
+		return &jobHandlerAdapter{handler: handler}, nil
+	})
+}
+
+// RegisterContext register the context handler factory by name
+func (r *Registry) RegisterContext(name string, factory ContextHandlerFactory) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("handler '%s' dumplicate registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// NewJob get a new job which handler built by the registered factory,
+// the handler name and params are kept to serialize the job
+func (r *Registry) NewJob(name, handler string, params map[string]interface{}) (*Job, error) {
+	r.m.RLock()
+	factory, ok := r.factories[handler]
+	r.m.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("handler '%s' not registered", handler)
+	}
+	contextHandler, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("handler '%s' %w", handler, err)
+	}
+	job := NewContextJob(name, contextHandler)
+	job.handlerName = handler
+	job.params = params
+	return job, nil
+}
@@ -0,0 +1,44 @@
+package gopool
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("result", func(params map[string]interface{}) (JobHandler, error) {
+		return &resultJob{result: params["result"]}, nil
+	})
+	registry.Register("fail", func(params map[string]interface{}) (JobHandler, error) {
+		if params["message"] == nil {
+			return nil, errors.New("message required")
+		}
+		return &failJob{}, nil
+	})
+	return registry
+}
+
+func TestRegistry(t *testing.T) {
+	registry := newTestRegistry()
+	if err := registry.Register("result", nil); err == nil {
+		t.Error("expect dumplicate registered error")
+	}
+	if _, err := registry.NewJob("job", "none", nil); err == nil {
+		t.Error("expect not registered error")
+	}
+	if _, err := registry.NewJob("job", "fail", nil); err == nil {
+		t.Error("expect factory error")
+	}
+	job, err := registry.NewJob("job", "result", map[string]interface{}{"result": "ok"})
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	p := NewPool(10, 2)
+	future, _ := p.SubmitJob(job)
+	if result, err := future.Wait(); result != "ok" || err != nil {
+		t.Errorf("unexpected result %v %v", result, err)
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,113 @@
+package gopool
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RejectPolicy what to do when add job into a full pool
+type RejectPolicy int
+
+const (
+	// RejectBlock block until the pool has room
+	RejectBlock RejectPolicy = iota
+	// RejectAbort return ErrPoolFull
+	RejectAbort
+	// RejectCallerRuns execute the job in the goroutine who add it
+	RejectCallerRuns
+	// RejectDiscardNewest discard the job being added
+	RejectDiscardNewest
+	// RejectDiscardOldest discard the earliest added pendding job
+	// and add the job into pool
+	RejectDiscardOldest
+)
+
+// RejectHandler handle the job rejected by the full pool,
+// the returned error is returned by AddJob
+type RejectHandler func(pool *Pool, job *Job) error
+
+func (r RejectPolicy) String() string {
+	switch r {
+	case RejectAbort:
+		return "abort"
+	case RejectCallerRuns:
+		return "caller runs"
+	case RejectDiscardNewest:
+		return "discard newest"
+	case RejectDiscardOldest:
+		return "discard oldest"
+	}
+	return "block"
+}
+
+// WithRejectPolicy set what to do when AddJob on a full pool,
+// default is RejectBlock
+func (p *Pool) WithRejectPolicy(policy RejectPolicy) *Pool {
+	p.rejectPolicy = policy
+	return p
+}
+
+// WithRejectHandler handle the jobs rejected by the full pool,
+// the reject policy is ignored when handler set
+func (p *Pool) WithRejectHandler(handler RejectHandler) *Pool {
+	p.rejectHandler = handler
+	return p
+}
+
+// RejectedJobs get the number of jobs rejected by the full pool
+func (p *Pool) RejectedJobs() uint64 {
+	return atomic.LoadUint64(&p.rejected)
+}
+
+// put add job into queue, handle the job by reject policy when full
+func (p *Pool) put(job *Job) error {
+	if p.rejectPolicy == RejectBlock && p.rejectHandler == nil {
+		p.jobs.put(job)
+		return nil
+	}
+	if p.jobs.tryPut(job) {
+		return nil
+	}
+
+	rejected := atomic.AddUint64(&p.rejected, 1)
+	policy := p.rejectPolicy.String()
+	if p.rejectHandler != nil {
+		policy = "handler"
+	}
+	p.sendEvent(EventLevelWarring,
+		fmt.Sprintf("job '%s' rejected by %s, pendding=%d, rejected=%d",
+			job, policy, p.PenddingJobs(), rejected))
+
+	if p.rejectHandler != nil {
+		if err := p.rejectHandler(p, job); err != nil {
+			return err
+		}
+		job.tracker.finish()
+		return nil
+	}
+	switch p.rejectPolicy {
+	case RejectAbort:
+		return ErrPoolFull
+	case RejectCallerRuns:
+		p.runJob("caller", job)
+	case RejectDiscardNewest:
+		p.discard(job)
+	case RejectDiscardOldest:
+		for !p.jobs.tryPut(job) {
+			oldest := p.jobs.evict()
+			if oldest == nil {
+				p.jobs.put(job)
+				break
+			}
+			p.discard(oldest)
+		}
+	}
+	return nil
+}
+
+// discard cancle the job rejected by the pool
+func (p *Pool) discard(job *Job) {
+	job.Cancle()
+	p.cancleJob(job)
+	p.sendEvent(EventLevelWarring, fmt.Sprintf("job '%s' discarded", job))
+}
@@ -0,0 +1,86 @@
+package gopool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlockedPool get a pool with one worker blocked and queue filled
+func newBlockedPool(t *testing.T, p *Pool) (*Pool, *Job, chan struct{}) {
+	block := make(chan struct{})
+	running := NewJob("block", &funcJob{f: func() { <-block }})
+	p.AddJob(running)
+	waitStatus(running, JobRunning, time.Second)
+	pendding := NewJob("pendding", &testJob{})
+	if err := p.AddJob(pendding); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	return p, pendding, block
+}
+
+func TestRejectAbort(t *testing.T) {
+	p, _, block := newBlockedPool(t, NewPool(1, 1).WithRejectPolicy(RejectAbort))
+	job := NewJob("rejected", &testJob{})
+	if err := p.AddJob(job); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if p.RejectedJobs() != 1 || job.IsTrigged() {
+		t.Errorf("rejected %d trigged %v", p.RejectedJobs(), job.IsTrigged())
+	}
+	close(block)
+	p.Close("finish")
+}
+
+func TestRejectCallerRuns(t *testing.T) {
+	p, _, block := newBlockedPool(t, NewPool(1, 1).WithRejectPolicy(RejectCallerRuns))
+	job := NewJob("caller", &testJob{})
+	if err := p.AddJob(job); err != nil {
+		t.Error(err.Error())
+	}
+	if job.GetStatus() != JobSuccess {
+		t.Errorf("job status %d, want success", job.GetStatus())
+	}
+	close(block)
+	p.Close("finish")
+}
+
+func TestRejectDiscard(t *testing.T) {
+	p, pendding, block := newBlockedPool(t, NewPool(1, 1).WithRejectPolicy(RejectDiscardNewest))
+	newest := NewJob("newest", &testJob{})
+	p.AddJob(newest)
+	if newest.GetStatus() != JobCancled || pendding.GetStatus() != JobPendding {
+		t.Errorf("newest status %d, pendding status %d", newest.GetStatus(), pendding.GetStatus())
+	}
+	close(block)
+	p.Close("finish")
+
+	p, pendding, block = newBlockedPool(t, NewPool(1, 1).WithRejectPolicy(RejectDiscardOldest))
+	newest = NewJob("newest", &testJob{})
+	p.AddJob(newest)
+	close(block)
+	p.Close("finish")
+	if newest.GetStatus() != JobSuccess || pendding.GetStatus() != JobCancled {
+		t.Errorf("newest status %d, pendding status %d", newest.GetStatus(), pendding.GetStatus())
+	}
+}
+
+func TestRejectHandler(t *testing.T) {
+	var warrings int32
+	errRejected := errors.New("rejected")
+	p, _, block := newBlockedPool(t, NewPool(1, 1).WithRejectHandler(func(pool *Pool, job *Job) error {
+		return errRejected
+	}).WithEventCallback(EventLevelWarring, func(event *Event) {
+		atomic.AddInt32(&warrings, 1)
+	}))
+	if err := p.AddJob(NewJob("rejected", &testJob{})); !errors.Is(err, errRejected) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if atomic.LoadInt32(&warrings) != 1 || p.RejectedJobs() != 1 {
+		t.Errorf("warrings %d rejected %d", warrings, p.RejectedJobs())
+	}
+	close(block)
+	p.Close("finish")
+}
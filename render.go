@@ -0,0 +1,127 @@
+package gopool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Render render the graph as text in layers, a job is in the layer after
+// the deepest of it's upstreams, every job is rendered once and marked as
+// join or fan-out when it has many upstreams or downstreams, annotate the
+// jobs with status, duration and error if status is true
+func (p *Pipeline) Render(status bool) string {
+	p.m.Lock()
+	var (
+		jobs      = append([]*Job{}, p.UniqueJobs...)
+		in        = make(map[*Job]bool, len(jobs))
+		parents   = make(map[*Job][]*Job, len(jobs))
+		childrens = make(map[*Job][]*Job, len(jobs))
+	)
+	for _, job := range jobs {
+		in[job] = true
+	}
+	for _, job := range jobs {
+		for _, children := range job.childrens {
+			if in[children] {
+				childrens[job] = append(childrens[job], children)
+				parents[children] = append(parents[children], job)
+			}
+		}
+	}
+	p.m.Unlock()
+
+	// the layer of every job is the longest path from the top jobs
+	var (
+		layers  = make(map[*Job]int, len(jobs))
+		waiting = make(map[*Job]int, len(jobs))
+		queue   []*Job
+		depth   int
+	)
+	for _, job := range jobs {
+		waiting[job] = len(parents[job])
+		if waiting[job] == 0 {
+			queue = append(queue, job)
+		}
+	}
+	for len(queue) > 0 {
+		job := queue[0]
+		queue = queue[1:]
+		for _, children := range childrens[job] {
+			if layers[job]+1 > layers[children] {
+				layers[children] = layers[job] + 1
+			}
+			if waiting[children]--; waiting[children] == 0 {
+				queue = append(queue, children)
+			}
+		}
+		if layers[job] > depth {
+			depth = layers[job]
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "pipeline %s\n", p.Name)
+	for layer := 0; layer <= depth && len(jobs) > 0; layer++ {
+		fmt.Fprintf(&b, "layer %d:\n", layer)
+		for _, job := range jobs {
+			if layers[job] != layer {
+				continue
+			}
+			b.WriteString("  " + job.Name)
+			var marks []string
+			if job.sub != nil {
+				marks = append(marks, "pipeline")
+			}
+			if len(parents[job]) > 1 {
+				marks = append(marks, "join")
+			}
+			if len(childrens[job]) > 1 {
+				marks = append(marks, "fan-out")
+			}
+			if len(marks) > 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(marks, ", "))
+			}
+			if status {
+				fmt.Fprintf(&b, " (%s)", renderState(job))
+			}
+			if len(parents[job]) > 1 {
+				fmt.Fprintf(&b, " <- %s", renderNames(parents[job]))
+			}
+			if len(childrens[job]) > 0 {
+				fmt.Fprintf(&b, " -> %s", renderNames(childrens[job]))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Render render the graph of the run as text, see Pipeline.Render
+func (r *PipelineRun) Render(status bool) string {
+	return r.run.Render(status)
+}
+
+// renderState the status, duration and error of the job
+func renderState(job *Job) string {
+	state := statusName(job.GetStatus())
+	start, end := job.GetStartTime(), job.GetEndTime()
+	if !start.IsZero() {
+		if end.IsZero() {
+			end = time.Now()
+		}
+		state += " " + end.Sub(start).Round(time.Millisecond).String()
+	}
+	if _, err := job.GetResult(); err != nil {
+		state += ", error: " + strings.ReplaceAll(err.Error(), "\n", " ")
+	}
+	return state
+}
+
+func renderNames(jobs []*Job) string {
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	return strings.Join(names, ", ")
+}
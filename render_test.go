@@ -0,0 +1,45 @@
+package gopool
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestPipelineRender(t *testing.T) {
+	pipeline := newDiamondPipeline(t)
+	e := NewJob("e", &testJob{})
+	e.After(pipeline.UniqueJobs[0])
+	pipeline, _ = NewPipeline("diamond", pipeline.UniqueJobs[0])
+	want := `pipeline diamond
+layer 0:
+  a [fan-out] -> b, c "quoted", e
+layer 1:
+  b -> d
+  c "quoted" -> d
+  e
+layer 2:
+  d [join] <- b, c "quoted"
+`
+	if text := pipeline.Render(false); text != want {
+		t.Errorf("unexpected text %s", text)
+	}
+
+	p := NewPool(10, 2)
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	want = `pipeline diamond
+layer 0:
+  a \[fan-out\] \(success \S+\) -> b, c "quoted", e
+layer 1:
+  b \(fail \S+, error: fail\) -> d
+  c "quoted" \(success \S+\) -> d
+  e \(success \S+\)
+layer 2:
+  d \[join\] \(skipped\) <- b, c "quoted"
+`
+	if text := run.Render(true); !regexp.MustCompile(want).MatchString(text) {
+		t.Errorf("unexpected text %s", text)
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,120 @@
+package gopool
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrPipelineRunning the pipeline run not finished
+var ErrPipelineRunning = errors.New("pipeline running")
+
+// ResumePipeline start a new run from the finished run, the jobs success
+// in the finished run are not executed again and their results are passed
+// to the downstreams, the failed, cancled and not executed jobs execute
+func (p *Pool) ResumePipeline(run *PipelineRun) (*PipelineRun, error) {
+	select {
+	case <-run.run.done:
+	default:
+		return nil, ErrPipelineRunning
+	}
+	resumed := run.resume()
+	pipeline := resumed.run
+	topJobs, err := pipeline.getTopJobs()
+	if err != nil {
+		return nil, err
+	}
+	if status := p.getStatus(); status == PoolExiting || status == PoolExited {
+		return nil, ErrPoolExit
+	}
+	pipeline.start()
+	// keep the run until all top jobs added or resolved
+	pipeline.tracker.add()
+	defer pipeline.tracker.finish()
+	return resumed, p.addPipelineJobs(topJobs)
+}
+
+// addPipelineJobs add the jobs into pool, the cached jobs are not
+// executed again, their downstreams are resolved instead
+func (p *Pool) addPipelineJobs(jobs []*Job) error {
+	var added []*Job
+	for _, job := range jobs {
+		if job.isCached() {
+			p.sendEvent(EventLevelDebug, fmt.Sprintf("job '%s' cached, skip", job))
+			p.addNextJobs(job)
+			continue
+		}
+		added = append(added, job)
+	}
+	return p.AddJob(added...)
+}
+
+// resume get a new run with the copies of the jobs of the run, the copies
+// of the success jobs keep the result if all their upstreams are cached,
+// so that all downstreams of the executed jobs execute again, the jobs
+// spawned by the executed jobs are dropped and will be spawned again
+func (r *PipelineRun) resume() *PipelineRun {
+	var (
+		jobs    = r.Jobs()
+		in      = make(map[*Job]bool, len(jobs))
+		cached  = make(map[*Job]bool)
+		dropped = make(map[*Job]bool)
+	)
+	for _, job := range jobs {
+		in[job] = true
+	}
+	// the upstreams are checked before the job
+	for _, job := range topological(jobs) {
+		if job.spawner != nil && (dropped[job.spawner] || !cached[job.spawner]) {
+			dropped[job] = true
+			continue
+		}
+		if job.GetStatus() != JobSuccess {
+			continue
+		}
+		cached[job] = true
+		for _, parent := range job.parents {
+			if in[parent] && !cached[parent] {
+				cached[job] = false
+			}
+		}
+	}
+
+	run, clones := r.run.cloneJobs(dropped)
+	resumed := &PipelineRun{
+		ID:       fmt.Sprintf("%s-%d", r.Pipeline.Name, atomic.AddUint64(&r.Pipeline.runs, 1)),
+		Pipeline: r.Pipeline,
+		From:     r.ID,
+		run:      run,
+	}
+	run.runID = resumed.ID
+	for job := range cached {
+		if cached[job] {
+			clones[job].cache(job)
+		}
+	}
+	return resumed
+}
+
+// cache mark the job success with the result of the success job
+func (j *Job) cache(job *Job) {
+	job.m.RLock()
+	defer job.m.RUnlock()
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.cached = true
+	j.trigged = true
+	j.status = JobSuccess
+	j.result = job.result
+	j.attempts = job.attempts
+	j.startTime = job.startTime
+	j.endTime = job.endTime
+	j.closeDone()
+}
+
+// isCached whether the job success in the run resumed from
+func (j *Job) isCached() bool {
+	j.m.RLock()
+	defer j.m.RUnlock()
+	return j.cached
+}
@@ -0,0 +1,170 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResumePipeline(t *testing.T) {
+	var checkouts, builds int32
+	p := NewPool(10, 2)
+	checkout := NewFuncJob("checkout", func() (interface{}, error) {
+		return atomic.AddInt32(&checkouts, 1), nil
+	})
+	build := NewFuncJob("build", func() (interface{}, error) {
+		if atomic.AddInt32(&builds, 1) == 1 {
+			return nil, errors.New("build fail")
+		}
+		return nil, nil
+	})
+	deploy := NewContextFuncJob("deploy", func(ctx context.Context) (interface{}, error) {
+		return InputsFromContext(ctx).Result("checkout"), nil
+	}).WithTriggerRule(TriggerAllSuccess)
+	checkout.Before(build, deploy)
+	deploy.After(build)
+	pipeline, _ := NewPipeline("resume", checkout)
+
+	run, _ := p.RunPipeline(pipeline)
+	result, _ := run.Wait(context.Background())
+	if result.Status != PipelineFailed || run.Job("deploy").GetStatus() != JobSkipped {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	resumed, err := p.ResumePipeline(run)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	result, _ = resumed.Wait(context.Background())
+	if result.Status != PipelineSuccess || resumed.From != run.ID || resumed.ID == run.ID {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, job := range result.Jobs {
+		if job.Cached != (job.Name == "checkout") || job.Status != JobSuccess {
+			t.Errorf("unexpected job result %+v", job)
+		}
+	}
+	if deployed, _ := resumed.Job("deploy").GetResult(); deployed != int32(1) || checkouts != 1 || builds != 2 {
+		t.Errorf("deployed %v checkouts %d builds %d", deployed, checkouts, builds)
+	}
+	p.Close("finish")
+}
+
+func TestResumePipelineRunning(t *testing.T) {
+	p := NewPool(10, 2)
+	pipeline, _ := NewPipeline("running", NewContextJob("block", &contextJob{}))
+	run, _ := p.RunPipeline(pipeline)
+	if _, err := p.ResumePipeline(run); err != ErrPipelineRunning {
+		t.Errorf("unexpected error %v", err)
+	}
+	run.Cancle()
+	run.Wait(context.Background())
+	resumed, err := p.ResumePipeline(run)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	resumed.Cancle()
+	if result, _ := resumed.Wait(context.Background()); result.Status != PipelineCancled {
+		t.Errorf("unexpected result %+v", result)
+	}
+	p.Close("finish")
+}
+
+func TestResumePipelineDownstreams(t *testing.T) {
+	var runs int32
+	p := NewPool(10, 2)
+	a := NewFuncJob("a", func() (interface{}, error) { return "A", nil })
+	b := NewFuncJob("b", func() (interface{}, error) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			return nil, errors.New("b fail")
+		}
+		return "B", nil
+	})
+	c := NewContextFuncJob("c", func(ctx context.Context) (interface{}, error) {
+		inputs := InputsFromContext(ctx)
+		return inputs.Result("b"), inputs.Err("b")
+	}).WithTriggerRule(TriggerAllDone)
+	d := NewContextFuncJob("d", func(ctx context.Context) (interface{}, error) {
+		return InputsFromContext(ctx).Result("a"), nil
+	})
+	a.Before(b, d)
+	c.After(b)
+	pipeline, _ := NewPipeline("resume", a)
+
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	if run.Job("c").GetStatus() != JobFail || run.Job("d").GetStatus() != JobSuccess {
+		t.Errorf("c status %d d status %d", run.Job("c").GetStatus(), run.Job("d").GetStatus())
+	}
+	resumed, _ := p.ResumePipeline(run)
+	result, _ := resumed.Wait(context.Background())
+	if result.Status != PipelineSuccess {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, job := range result.Jobs {
+		if job.Cached != (job.Name == "a" || job.Name == "d") {
+			t.Errorf("unexpected job result %+v", job)
+		}
+	}
+	if c, err := resumed.Job("c").GetResult(); c != "B" || err != nil {
+		t.Errorf("unexpected c result %v %v", c, err)
+	}
+
+	// the success downstream of the executed job execute again
+	var executed int32
+	cached := NewFuncJob("c", func() (interface{}, error) {
+		return atomic.AddInt32(&executed, 1), nil
+	})
+	runs = 0
+	b.childrens, c.parents = nil, nil
+	b.Before(cached)
+	pipeline, _ = NewPipeline("chain", a)
+	run, _ = p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	if run.Job("c").GetStatus() != JobSuccess {
+		t.Errorf("unexpected c status %d", run.Job("c").GetStatus())
+	}
+	resumed, _ = p.ResumePipeline(run)
+	resumed.Wait(context.Background())
+	if job := resumed.Job("c"); job.isCached() || atomic.LoadInt32(&executed) != 2 {
+		t.Errorf("c cached %v executed %d", job.isCached(), executed)
+	}
+	p.Close("finish")
+}
+
+func TestResumePipelineSpawned(t *testing.T) {
+	var runs int32
+	p := NewPool(10, 2)
+	list := NewContextFuncJob("list", func(ctx context.Context) (interface{}, error) {
+		for i := 0; i < 2; i++ {
+			if err := Spawn(ctx, NewJob(fmt.Sprintf("proc-%d", i), &testJob{})); err != nil {
+				return nil, err
+			}
+		}
+		if atomic.AddInt32(&runs, 1) == 1 {
+			return nil, errors.New("list fail")
+		}
+		return nil, nil
+	})
+	join := NewJob("join", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	join.After(list)
+	pipeline, _ := NewPipeline("spawn", list)
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+
+	resumed, _ := p.ResumePipeline(run)
+	result, _ := resumed.Wait(context.Background())
+	if result.Status != PipelineSuccess || len(result.Jobs) != 4 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, job := range result.Jobs {
+		if job.Cached || job.Status != JobSuccess {
+			t.Errorf("unexpected job result %+v", job)
+		}
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,71 @@
+package gopool
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffType the way to compute the delay between attempts
+type BackoffType int
+
+const (
+	// BackoffFixed wait the same delay before each retry
+	BackoffFixed BackoffType = iota
+	// BackoffExponential double the delay after each retry
+	BackoffExponential
+)
+
+func (b BackoffType) String() string {
+	if b == BackoffExponential {
+		return "exponential"
+	}
+	return "fixed"
+}
+
+// RetryPolicy job retry policy
+type RetryPolicy struct {
+	// MaxAttempts the max execute times include the first one
+	MaxAttempts int
+	// Backoff fixed or exponential backoff
+	Backoff BackoffType
+	// Delay the delay before the first retry
+	Delay time.Duration
+	// MaxDelay the max delay between attempts, no limit if zero
+	MaxDelay time.Duration
+	// Jitter randomize the delay in [delay*(1-Jitter), delay*(1+Jitter)],
+	// should between 0 and 1
+	Jitter float64
+	// Retryable whether the error should be retried,
+	// all errors will be retried if nil
+	Retryable func(err error) bool
+}
+
+// shouldRetry whether retry after the attempt failed with err
+func (r *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if attempt >= r.MaxAttempts {
+		return false
+	}
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return true
+}
+
+// delay get the delay before the next attempt
+func (r *RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(r.Delay)
+	if r.Backoff == BackoffExponential {
+		delay *= math.Pow(2, float64(attempt-1))
+	}
+	if r.MaxDelay > 0 && delay > float64(r.MaxDelay) {
+		delay = float64(r.MaxDelay)
+	}
+	if r.Jitter > 0 {
+		delay += delay * r.Jitter * (rand.Float64()*2 - 1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,123 @@
+package gopool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+type flakyJob struct {
+	// fail times before success
+	fails int32
+	calls int32
+}
+
+func (j *flakyJob) Handle() (interface{}, error) {
+	if atomic.AddInt32(&j.calls, 1) <= j.fails {
+		return nil, errFlaky
+	}
+	return "ok", nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     BackoffExponential,
+		Delay:       10 * time.Millisecond,
+		MaxDelay:    30 * time.Millisecond,
+	}
+	for attempt, want := range []time.Duration{
+		10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond,
+	} {
+		if delay := policy.delay(attempt + 1); delay != want {
+			t.Errorf("attempt %d delay %s, want %s", attempt+1, delay, want)
+		}
+	}
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := policy.delay(1); delay < 5*time.Millisecond || delay > 15*time.Millisecond {
+			t.Errorf("jitter delay %s out of range", delay)
+			t.FailNow()
+		}
+	}
+}
+
+func TestRetrySuccess(t *testing.T) {
+	p := NewPool(10, 2)
+	handler := &flakyJob{fails: 2}
+	job := NewJob("flaky", handler).WithRetry(&RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     BackoffExponential,
+		Delay:       10 * time.Millisecond,
+	})
+	p.AddJob(job)
+	if !waitStatus(job, JobSuccess, time.Second) {
+		t.Errorf("job status %d, want success", job.GetStatus())
+		t.FailNow()
+	}
+	if job.Attempts() != 3 {
+		t.Errorf("job attempts %d, want 3", job.Attempts())
+	}
+	p.Close("finish")
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var retryEvents int32
+	p := NewPool(10, 2).WithEventCallback(EventLevelWarring, func(event *Event) {
+		if event.Level() == EventLevelWarring {
+			atomic.AddInt32(&retryEvents, 1)
+		}
+	})
+	job := NewJob("flaky", &flakyJob{fails: 10}).WithRetry(&RetryPolicy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+	})
+	p.AddJob(job)
+	waitStatus(job, JobFail, time.Second)
+	p.Close("finish")
+	if job.GetStatus() != JobFail || job.Attempts() != 3 {
+		t.Errorf("job status %d attempts %d", job.GetStatus(), job.Attempts())
+	}
+	if atomic.LoadInt32(&retryEvents) != 2 {
+		t.Errorf("retry events %d, want 2", retryEvents)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewJob("flaky", &flakyJob{fails: 10}).WithRetry(&RetryPolicy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+		Retryable: func(err error) bool {
+			return !errors.Is(err, errFlaky)
+		},
+	})
+	p.AddJob(job)
+	p.Close("finish")
+	if job.GetStatus() != JobFail || job.Attempts() != 1 {
+		t.Errorf("job status %d attempts %d", job.GetStatus(), job.Attempts())
+	}
+}
+
+func TestRetryClose(t *testing.T) {
+	p := NewPool(10, 2)
+	handler := &flakyJob{fails: 10}
+	job := NewJob("flaky", handler).WithRetry(&RetryPolicy{
+		MaxAttempts: 3,
+		Delay:       50 * time.Millisecond,
+	})
+	p.AddJob(job)
+	for atomic.LoadInt32(&handler.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.Close("finish")
+	if calls := atomic.LoadInt32(&handler.calls); calls != 1 || job.GetStatus() != JobFail {
+		t.Errorf("job status %d calls %d, want fail without retry", job.GetStatus(), calls)
+	}
+	if _, err := NewFuture(job).Wait(); !errors.Is(err, errFlaky) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package gopool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// PipelineRun a run of the pipeline, the jobs of the run are fresh
+// copies of the pipeline jobs, so that the same pipeline can be run
+// many times concurrently without interference, the handlers are
+// shared by the runs
+type PipelineRun struct {
+	ID string
+	// the pipeline define
+	Pipeline *Pipeline
+	// the id of the run resumed from, empty if not resumed
+	From string
+	// the pipeline carry the jobs state of this run
+	run *Pipeline
+}
+
+// RunPipeline start a new run of the pipeline, the pipeline jobs
+// keep no execute state so the pipeline can be run again
+func (p *Pool) RunPipeline(pipeline *Pipeline) (*PipelineRun, error) {
+	run := pipeline.newRun()
+	return run, p.AddPipeline(run.run)
+}
+
+// newRun get a new run with fresh jobs and a unique run id
+func (p *Pipeline) newRun() *PipelineRun {
+	run := &PipelineRun{
+		ID:       fmt.Sprintf("%s-%d", p.Name, atomic.AddUint64(&p.runs, 1)),
+		Pipeline: p,
+		run:      p.clone(),
+	}
+	run.run.runID = run.ID
+	return run
+}
+
+// Wait wait all triggered jobs of the run finished,
+// return the context error if the context done first
+func (r *PipelineRun) Wait(ctx context.Context) (*PipelineResult, error) {
+	return r.run.Wait(ctx)
+}
+
+// Cancle cancle the jobs of the run to execute
+func (r *PipelineRun) Cancle() {
+	r.run.Cancle()
+}
+
+// Jobs get the jobs of the run include the spawned jobs
+func (r *PipelineRun) Jobs() []*Job {
+	r.run.m.Lock()
+	defer r.run.m.Unlock()
+	return append([]*Job{}, r.run.UniqueJobs...)
+}
+
+// Job get the job of the run by name, nil if not found
+func (r *PipelineRun) Job(name string) *Job {
+	for _, job := range r.Jobs() {
+		if job.Name == name {
+			return job
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package gopool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunPipeline(t *testing.T) {
+	p := NewPool(10, 4)
+	job1 := NewJob("job1", &resultJob{result: "job1", d: 20 * time.Millisecond})
+	job2 := NewJob("job2", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	job2.After(job1)
+	pipeline, _ := NewPipeline("run", job1)
+
+	var runs []*PipelineRun
+	for i := 0; i < 3; i++ {
+		run, err := p.RunPipeline(pipeline)
+		if err != nil {
+			t.Error(err.Error())
+			t.FailNow()
+		}
+		runs = append(runs, run)
+	}
+	ids := make(map[string]bool)
+	for _, run := range runs {
+		result, err := run.Wait(context.Background())
+		if err != nil || result.Status != PipelineSuccess || result.RunID != run.ID {
+			t.Errorf("unexpected result %+v %v", result, err)
+		}
+		if ids[run.ID] {
+			t.Errorf("duplicate run id %s", run.ID)
+		}
+		ids[run.ID] = true
+		if result, _ := run.Job("job1").GetResult(); result != "job1" {
+			t.Errorf("unexpected job1 result %v", result)
+		}
+		if run.Job("job2").GetStatus() != JobSuccess {
+			t.Errorf("unexpected job2 status %d", run.Job("job2").GetStatus())
+		}
+	}
+	if job1.GetStatus() != JobPendding || job2.GetStatus() != JobPendding {
+		t.Errorf("pipeline jobs executed %d %d", job1.GetStatus(), job2.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestRunPipelineCancle(t *testing.T) {
+	p := NewPool(10, 4)
+	block := NewContextJob("block", &contextJob{})
+	pipeline, _ := NewPipeline("cancle", block)
+	cancled, _ := p.RunPipeline(pipeline)
+	run, _ := p.RunPipeline(pipeline)
+	cancled.Cancle()
+	result, err := cancled.Wait(context.Background())
+	if err != nil || result.Status != PipelineCancled {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := run.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error %v", err)
+	}
+	run.Cancle()
+	p.Close("finish")
+}
@@ -0,0 +1,87 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotInPipeline the job not executed in pipeline
+	ErrNotInPipeline = errors.New("job not in pipeline")
+	// ErrNotInRun the job executed in pipeline added by AddPipeline,
+	// spawn jobs would change the pipeline define
+	ErrNotInRun = errors.New("job not in pipeline run")
+)
+
+// spawnerKey the context key of the job spawn new jobs
+type spawnerKey struct{}
+
+// Spawn insert the jobs as downstreams of the running job in the current
+// pipeline run, the downstreams of the running job become the join of the
+// spawned jobs, they wait all spawned jobs resolved, the spawned jobs
+// must not be linked to other jobs and their names must be unique in the
+// run, return ErrNotInPipeline when the context not passed to a job executed
+// in pipeline, ErrNotInRun when the pipeline not started by RunPipeline
+func Spawn(ctx context.Context, jobs ...*Job) error {
+	job, _ := ctx.Value(spawnerKey{}).(*Job)
+	if job == nil || job.pipeline == nil {
+		return ErrNotInPipeline
+	}
+	return job.pipeline.spawn(job, jobs)
+}
+
+// spawn link the jobs between the job and it's downstreams, the spawned
+// jobs are not linked and not in pipeline so that no cycle can be added
+func (p *Pipeline) spawn(job *Job, spawned []*Job) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.runID == "" {
+		return ErrNotInRun
+	}
+	if job.GetStatus() != JobRunning {
+		return fmt.Errorf("job '%s' spawn jobs while not running", job)
+	}
+	// the inputs of the joins are keyed by upstream name
+	names := make(map[string]bool, len(p.UniqueJobs))
+	for _, other := range p.UniqueJobs {
+		names[other.Name] = true
+	}
+	for _, children := range spawned {
+		if _, ok := p.joins[children]; ok {
+			return fmt.Errorf("cycle added %s", jobs{job, children})
+		}
+		if len(children.parents) != 0 || len(children.childrens) != 0 {
+			return fmt.Errorf("job '%s' already linked", children)
+		}
+		if names[children.Name] {
+			return fmt.Errorf("job '%s' dumplicate added", children)
+		}
+		names[children.Name] = true
+	}
+
+	var joins []*Job
+	for _, children := range job.childrens {
+		if _, ok := p.joins[children]; ok {
+			joins = append(joins, children)
+		}
+	}
+	for _, children := range spawned {
+		for _, join := range joins {
+			join.parents = append(join.parents, children)
+			children.childrens = append(children.childrens, join)
+			p.joins[join]++
+		}
+		children.parents = append(children.parents, job)
+		job.childrens = append(job.childrens, children)
+		children.spawner = job
+		children.tracker = p.tracker
+		children.pipeline = p
+		p.joins[children] = 1
+		p.UniqueJobs = append(p.UniqueJobs, children)
+		if p.cancled || p.failed {
+			children.Cancle()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package gopool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestSpawn(t *testing.T) {
+	p := NewPool(10, 3)
+	list := NewContextFuncJob("list", func(ctx context.Context) (interface{}, error) {
+		for i := 0; i < 5; i++ {
+			process := NewJob(fmt.Sprintf("process-%d", i), &resultJob{result: i})
+			if err := Spawn(ctx, process); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	join := NewContextFuncJob("join", func(ctx context.Context) (interface{}, error) {
+		sum := 0
+		for name, input := range InputsFromContext(ctx) {
+			if name != "list" {
+				sum += input.Result.(int)
+			}
+		}
+		return sum, nil
+	}).WithTriggerRule(TriggerAllSuccess)
+	join.After(list)
+	pipeline, _ := NewPipeline("spawn", list)
+	run, _ := p.RunPipeline(pipeline)
+	result, err := run.Wait(context.Background())
+	if err != nil || result.Status != PipelineSuccess || len(result.Jobs) != 7 {
+		t.Errorf("unexpected result %+v %v", result, err)
+		t.FailNow()
+	}
+	if sum, err := run.Job("join").GetResult(); sum != 10 || err != nil {
+		t.Errorf("unexpected join result %v %v", sum, err)
+	}
+	for _, job := range run.Jobs() {
+		if job.Name != "join" && run.Job("join").GetStartTime().Before(job.GetEndTime()) {
+			t.Errorf("join started before %s finished", job)
+		}
+	}
+	if len(list.GetDownstreams()) != 1 {
+		t.Errorf("pipeline define changed %v", list.GetDownstreams())
+	}
+	p.Close("finish")
+}
+
+func TestSpawnError(t *testing.T) {
+	p := NewPool(10, 3)
+	future, _ := p.Submit(JobHandlerFunc(func() (interface{}, error) {
+		return nil, Spawn(context.Background(), NewJob("job", &testJob{}))
+	}))
+	if _, err := future.Wait(); err != ErrNotInPipeline {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	var errs []error
+	spawner := NewContextFuncJob("spawner", func(ctx context.Context) (interface{}, error) {
+		errs = append(errs,
+			Spawn(ctx, NewJob("next", &testJob{})),
+			Spawn(ctx, NewJob("other", &testJob{})),
+			Spawn(ctx, NewJob("spawned", &testJob{}), NewJob("spawned", &testJob{})),
+		)
+		return nil, nil
+	})
+	other := NewJob("other", &resultJob{result: "real other"})
+	next := NewContextFuncJob("next", func(ctx context.Context) (interface{}, error) {
+		return InputsFromContext(ctx).Result("other"), nil
+	})
+	next.After(spawner, other)
+	pipeline, _ := NewPipeline("spawn", spawner, other)
+	run, _ := p.RunPipeline(pipeline)
+	run.Wait(context.Background())
+	for index, err := range errs {
+		if err == nil {
+			t.Errorf("spawn %d expect error", index)
+		}
+	}
+	if result, _ := run.Job("next").GetResult(); result != "real other" || len(run.Jobs()) != 3 {
+		t.Errorf("unexpected next result %v jobs %v", result, run.Jobs())
+	}
+
+	errs = nil
+	p.AddPipeline(pipeline)
+	pipeline.Wait(context.Background())
+	if len(errs) != 3 || errs[0] != ErrNotInRun || len(spawner.GetDownstreams()) != 1 {
+		t.Errorf("unexpected errors %v downstreams %v", errs, spawner.GetDownstreams())
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,272 @@
+package gopool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineSpec the declaration of a pipeline
+type PipelineSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// continue or fail_fast
+	ErrorPolicy string    `json:"error_policy,omitempty" yaml:"error_policy,omitempty"`
+	Jobs        []JobSpec `json:"jobs" yaml:"jobs"`
+}
+
+// JobSpec the declaration of a job
+type JobSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// the name of the registered handler factory
+	Handler string                 `json:"handler" yaml:"handler"`
+	Params  map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	// the names of the upstreams
+	After []string `json:"after,omitempty" yaml:"after,omitempty"`
+	// the name of the trigger rule, such as all_success
+	TriggerRule  string     `json:"trigger_rule,omitempty" yaml:"trigger_rule,omitempty"`
+	Once         bool       `json:"once,omitempty" yaml:"once,omitempty"`
+	Timeout      string     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Priority     int        `json:"priority,omitempty" yaml:"priority,omitempty"`
+	AllowFailure bool       `json:"allow_failure,omitempty" yaml:"allow_failure,omitempty"`
+	Retry        *RetrySpec `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// RetrySpec the declaration of the retry policy,
+// the Retryable predicate can't be declared
+type RetrySpec struct {
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+	// fixed or exponential
+	Backoff  string  `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	Delay    string  `json:"delay,omitempty" yaml:"delay,omitempty"`
+	MaxDelay string  `json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+	Jitter   float64 `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+}
+
+// SpecError the declaration of the job invalid
+type SpecError struct {
+	// the name of the job, or the index if the name is empty
+	Job string
+	Err error
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("job '%s': %s", e.Job, e.Err.Error())
+}
+
+// Unwrap get the reason
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// LoadJSON build the pipeline declared in JSON
+func (r *Registry) LoadJSON(data []byte) (*Pipeline, error) {
+	var spec PipelineSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return r.Load(&spec)
+}
+
+// LoadYAML build the pipeline declared in YAML
+func (r *Registry) LoadYAML(data []byte) (*Pipeline, error) {
+	var spec PipelineSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return r.Load(&spec)
+}
+
+// Load build the pipeline by the declaration, return *SpecError
+// point to the job when the declaration of the job invalid
+func (r *Registry) Load(spec *PipelineSpec) (*Pipeline, error) {
+	errorPolicy, err := parseErrorPolicy(spec.ErrorPolicy)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		jobs  []*Job
+		named = make(map[string]*Job)
+	)
+	for index, jobSpec := range spec.Jobs {
+		if jobSpec.Name == "" {
+			return nil, &SpecError{Job: fmt.Sprintf("#%d", index), Err: errors.New("name required")}
+		}
+		if _, ok := named[jobSpec.Name]; ok {
+			return nil, &SpecError{Job: jobSpec.Name, Err: errors.New("dumplicate declared")}
+		}
+		job, err := r.newJob(&jobSpec)
+		if err != nil {
+			return nil, &SpecError{Job: jobSpec.Name, Err: err}
+		}
+		named[job.Name] = job
+		jobs = append(jobs, job)
+	}
+	for index, jobSpec := range spec.Jobs {
+		for _, name := range jobSpec.After {
+			upstream, ok := named[name]
+			if !ok {
+				return nil, &SpecError{Job: jobSpec.Name,
+					Err: fmt.Errorf("upstream '%s' not declared", name)}
+			}
+			if err := jobs[index].After(upstream); err != nil {
+				return nil, &SpecError{Job: jobSpec.Name, Err: err}
+			}
+		}
+	}
+	pipeline, err := NewPipeline(spec.Name, jobs...)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.WithErrorPolicy(errorPolicy), nil
+}
+
+// newJob build the job by the declaration
+func (r *Registry) newJob(spec *JobSpec) (*Job, error) {
+	job, err := r.NewJob(spec.Name, spec.Handler, spec.Params)
+	if err != nil {
+		return nil, err
+	}
+	if job.triggerRule, err = parseTriggerRule(spec.TriggerRule); err != nil {
+		return nil, err
+	}
+	if job.timeout, err = parseDuration("timeout", spec.Timeout); err != nil {
+		return nil, err
+	}
+	job.once = spec.Once
+	job.priority = spec.Priority
+	job.allowFailure = spec.AllowFailure
+	if spec.Retry == nil {
+		return job, nil
+	}
+	job.retry = &RetryPolicy{
+		MaxAttempts: spec.Retry.MaxAttempts,
+		Jitter:      spec.Retry.Jitter,
+	}
+	if job.retry.Backoff, err = parseBackoff(spec.Retry.Backoff); err != nil {
+		return nil, err
+	}
+	if job.retry.Delay, err = parseDuration("retry delay", spec.Retry.Delay); err != nil {
+		return nil, err
+	}
+	if job.retry.MaxDelay, err = parseDuration("retry max delay", spec.Retry.MaxDelay); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Spec get the declaration of the pipeline, all jobs should be
+// created by Registry.NewJob or loaded and have no When condition or
+// Retryable predicate
+func (p *Pipeline) Spec() (*PipelineSpec, error) {
+	spec := &PipelineSpec{Name: p.Name}
+	if p.errorPolicy != ErrorContinue {
+		spec.ErrorPolicy = p.errorPolicy.String()
+	}
+	for _, job := range p.UniqueJobs {
+		if job.handlerName == "" {
+			return nil, &SpecError{Job: job.Name, Err: errors.New("handler not registered")}
+		}
+		if job.when != nil {
+			return nil, &SpecError{Job: job.Name, Err: errors.New("When condition can't be serialized")}
+		}
+		if job.retry != nil && job.retry.Retryable != nil {
+			return nil, &SpecError{Job: job.Name, Err: errors.New("Retryable predicate can't be serialized")}
+		}
+		jobSpec := JobSpec{
+			Name:         job.Name,
+			Handler:      job.handlerName,
+			Params:       job.params,
+			Once:         job.once,
+			Priority:     job.priority,
+			AllowFailure: job.allowFailure,
+		}
+		for _, parent := range job.parents {
+			jobSpec.After = append(jobSpec.After, parent.Name)
+		}
+		if job.triggerRule != TriggerDefault {
+			jobSpec.TriggerRule = job.triggerRule.String()
+		}
+		if job.timeout > 0 {
+			jobSpec.Timeout = job.timeout.String()
+		}
+		if job.retry != nil {
+			jobSpec.Retry = &RetrySpec{
+				MaxAttempts: job.retry.MaxAttempts,
+				Backoff:     job.retry.Backoff.String(),
+				Jitter:      job.retry.Jitter,
+			}
+			if job.retry.Delay > 0 {
+				jobSpec.Retry.Delay = job.retry.Delay.String()
+			}
+			if job.retry.MaxDelay > 0 {
+				jobSpec.Retry.MaxDelay = job.retry.MaxDelay.String()
+			}
+		}
+		spec.Jobs = append(spec.Jobs, jobSpec)
+	}
+	return spec, nil
+}
+
+// ToJSON serialize the pipeline to JSON, see Spec
+func (p *Pipeline) ToJSON() ([]byte, error) {
+	spec, err := p.Spec()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// ToYAML serialize the pipeline to YAML, see Spec
+func (p *Pipeline) ToYAML() ([]byte, error) {
+	spec, err := p.Spec()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(spec)
+}
+
+func parseTriggerRule(name string) (TriggerRule, error) {
+	if name == "" {
+		return TriggerDefault, nil
+	}
+	for rule := TriggerDefault; rule <= TriggerNoneSkipped; rule++ {
+		if rule.String() == name {
+			return rule, nil
+		}
+	}
+	return TriggerDefault, fmt.Errorf("unknown trigger rule '%s'", name)
+}
+
+func parseErrorPolicy(name string) (ErrorPolicy, error) {
+	switch name {
+	case "", ErrorContinue.String():
+		return ErrorContinue, nil
+	case ErrorFailFast.String():
+		return ErrorFailFast, nil
+	}
+	return ErrorContinue, fmt.Errorf("unknown error policy '%s'", name)
+}
+
+func parseBackoff(name string) (BackoffType, error) {
+	switch name {
+	case "", BackoffFixed.String():
+		return BackoffFixed, nil
+	case BackoffExponential.String():
+		return BackoffExponential, nil
+	}
+	return BackoffFixed, fmt.Errorf("unknown backoff '%s'", name)
+}
+
+func parseDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return d, nil
+}
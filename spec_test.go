@@ -0,0 +1,143 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPipelineYAML = `
+name: build
+error_policy: fail_fast
+jobs:
+  - name: checkout
+    handler: result
+    params:
+      result: ok
+  - name: lint
+    handler: fail
+    params:
+      message: lint fail
+    after: [checkout]
+    allow_failure: true
+  - name: test
+    handler: result
+    after: [checkout]
+    timeout: 1s
+    retry:
+      max_attempts: 3
+      backoff: exponential
+      delay: 10ms
+  - name: deploy
+    handler: result
+    after: [lint, test]
+    trigger_rule: all_done
+    once: true
+    priority: 2
+`
+
+func TestLoadYAML(t *testing.T) {
+	registry := newTestRegistry()
+	pipeline, err := registry.LoadYAML([]byte(testPipelineYAML))
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	graph, _ := pipeline.Graph()
+	if graph != "checkout -> lint -> deploy -> test -> deploy\n" {
+		t.Errorf("unexpected graph %q", graph)
+	}
+	if pipeline.errorPolicy != ErrorFailFast {
+		t.Errorf("unexpected error policy %s", pipeline.errorPolicy)
+	}
+	run := map[string]*Job{}
+	for _, job := range pipeline.UniqueJobs {
+		run[job.Name] = job
+	}
+	if test := run["test"]; test.timeout != time.Second || test.retry.MaxAttempts != 3 ||
+		test.retry.Backoff != BackoffExponential || test.retry.Delay != 10*time.Millisecond {
+		t.Errorf("unexpected test job %+v", test)
+	}
+	if deploy := run["deploy"]; deploy.triggerRule != TriggerAllDone || !deploy.IsOnce() || deploy.GetPriority() != 2 {
+		t.Errorf("unexpected deploy job %+v", deploy)
+	}
+
+	p := NewPool(10, 2)
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineSuccess || run["deploy"].GetStatus() != JobSuccess {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	p.Close("finish")
+}
+
+func TestLoadJSONError(t *testing.T) {
+	registry := newTestRegistry()
+	cases := map[string]string{
+		`{"jobs": [{"handler": "result"}]}`:                                                  "#0",
+		`{"jobs": [{"name": "a", "handler": "none"}]}`:                                       "a",
+		`{"jobs": [{"name": "a", "handler": "fail"}]}`:                                       "a",
+		`{"jobs": [{"name": "a", "handler": "result", "after": ["b"]}]}`:                     "a",
+		`{"jobs": [{"name": "a", "handler": "result", "trigger_rule": "none"}]}`:             "a",
+		`{"jobs": [{"name": "a", "handler": "result", "timeout": "1"}]}`:                     "a",
+		`{"jobs": [{"name": "a", "handler": "result"}, {"name": "a", "handler": "result"}]}`: "a",
+		`{"jobs": [{"name": "a", "handler": "result", "after": ["b"]},
+			{"name": "b", "handler": "result", "after": ["a"]}]}`: "b",
+	}
+	for data, name := range cases {
+		_, err := registry.LoadJSON([]byte(data))
+		var specErr *SpecError
+		if !errors.As(err, &specErr) || specErr.Job != name {
+			t.Errorf("%s: unexpected error %v", data, err)
+		}
+	}
+	if _, err := registry.LoadJSON([]byte(`{"error_policy": "none"}`)); err == nil {
+		t.Error("expect unknown error policy error")
+	}
+}
+
+func TestPipelineSerialize(t *testing.T) {
+	registry := newTestRegistry()
+	pipeline, _ := registry.LoadYAML([]byte(testPipelineYAML))
+	data, err := pipeline.ToJSON()
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	loaded, err := registry.LoadJSON(data)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	want, _ := pipeline.ToYAML()
+	got, _ := loaded.ToYAML()
+	if string(want) != string(got) {
+		t.Errorf("unexpected yaml %s, want %s", got, want)
+	}
+	if !strings.Contains(string(got), "trigger_rule: all_done") ||
+		!strings.Contains(string(got), "error_policy: fail_fast") {
+		t.Errorf("unexpected yaml %s", got)
+	}
+
+	pipeline, _ = NewPipeline("code", NewJob("job", &testJob{}))
+	if _, err := pipeline.ToJSON(); err == nil {
+		t.Error("expect handler not registered error")
+	}
+
+	job, _ := registry.NewJob("when", "result", nil)
+	job.When(func(self *Job) bool { return false })
+	pipeline, _ = NewPipeline("when", job)
+	var specErr *SpecError
+	if _, err := pipeline.ToYAML(); !errors.As(err, &specErr) || specErr.Job != "when" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	job, _ = registry.NewJob("retryable", "result", nil)
+	job.WithRetry(&RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool { return false }})
+	pipeline, _ = NewPipeline("retryable", job)
+	if _, err := pipeline.ToJSON(); !errors.As(err, &specErr) || specErr.Job != "retryable" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPipelineFailed the pipeline executed as a job failed
+	ErrPipelineFailed = errors.New("pipeline failed")
+	// ErrPipelineCancled the pipeline executed as a job cancled
+	ErrPipelineCancled = errors.New("pipeline cancled")
+)
+
+// NewPipelineJob wrap the pipeline as a job, so that the pipeline can be
+// a node of another pipeline, every execute of the job start a new run of
+// the pipeline and the job finish when the run finished, the result of the
+// job is the *PipelineResult of the run
+func NewPipelineJob(name string, pipeline *Pipeline) *Job {
+	job := NewContextJob(name, nil)
+	job.sub = pipeline
+	return job
+}
+
+// runPipelineJob start a new run of the pipeline of the job, the worker
+// is released without waiting the run, the job finish when the run finished
+func (p *Pool) runPipelineJob(ctx context.Context, job *Job) {
+	cancel := func() {}
+	if job.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, job.timeout)
+	}
+	var (
+		run    = job.sub.newRun()
+		addErr error
+	)
+	run.run.onDone = func() {
+		result := run.run.result()
+		var err error
+		switch {
+		case addErr != nil:
+			err = addErr
+		case ctx.Err() == context.DeadlineExceeded:
+			err = ErrJobTimeout
+		case result.Status == PipelineCancled:
+			err = fmt.Errorf("%s %w", run.ID, ErrPipelineCancled)
+		case result.Status == PipelineFailed:
+			err = fmt.Errorf("%s %w", run.ID, ErrPipelineFailed)
+		}
+		cancel()
+		p.finishJob(job, result, err)
+	}
+	run.run.start()
+	done := run.run.done
+	go func() {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				p.sendEvent(EventLevelWarring,
+					fmt.Sprintf("job '%s' timeout after %s", job, job.timeout))
+			}
+			run.Cancle()
+		case <-done:
+		}
+	}()
+	// keep the run until the error of adding recorded
+	run.run.tracker.add()
+	if err := p.AddPipeline(run.run); err != nil {
+		addErr = err
+	}
+	run.run.tracker.finish()
+}
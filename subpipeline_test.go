@@ -0,0 +1,90 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPipelineJob(t *testing.T) {
+	p := NewPool(10, 1)
+	a := NewJob("a", &resultJob{result: "a", d: 10 * time.Millisecond})
+	b := NewJob("b", &resultJob{result: "b", d: 10 * time.Millisecond})
+	b.After(a)
+	sub, _ := NewPipeline("sub", a)
+
+	prepare := NewJob("prepare", &testJob{})
+	build := NewPipelineJob("build", sub)
+	deploy := NewJob("deploy", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	build.After(prepare)
+	deploy.After(build)
+	pipeline, _ := NewPipeline("outer", prepare)
+	graph, _ := pipeline.Graph()
+	if graph != "prepare -> build{a -> b} -> deploy\n" {
+		t.Errorf("unexpected graph %q", graph)
+	}
+
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineSuccess {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	if deploy.GetStatus() != JobSuccess {
+		t.Errorf("unexpected deploy status %d", deploy.GetStatus())
+	}
+	subResult, _ := build.GetResult()
+	if run, ok := subResult.(*PipelineResult); !ok || run.Status != PipelineSuccess || len(run.Jobs) != 2 {
+		t.Errorf("unexpected sub pipeline result %+v", subResult)
+	} else if deploy.GetStartTime().Before(run.EndTime) {
+		t.Error("deploy started before sub pipeline finished")
+	}
+	if a.GetStatus() != JobPendding {
+		t.Errorf("sub pipeline jobs executed %d", a.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestPipelineJobFailed(t *testing.T) {
+	p := NewPool(10, 2)
+	sub, _ := NewPipeline("sub", NewJob("fail", &failJob{}))
+	build := NewPipelineJob("build", sub)
+	deploy := NewJob("deploy", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	deploy.After(build)
+	pipeline, _ := NewPipeline("outer", build)
+	p.AddPipeline(pipeline)
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineFailed {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+	if _, err := build.GetResult(); !errors.Is(err, ErrPipelineFailed) || build.GetStatus() != JobFail {
+		t.Errorf("build status %d error %v", build.GetStatus(), err)
+	}
+	if deploy.GetStatus() != JobSkipped {
+		t.Errorf("unexpected deploy status %d", deploy.GetStatus())
+	}
+	p.Close("finish")
+}
+
+func TestPipelineJobCancle(t *testing.T) {
+	p := NewPool(10, 2)
+	sub, _ := NewPipeline("sub", NewContextJob("block", &contextJob{}))
+	build := NewPipelineJob("build", sub)
+	pipeline, _ := NewPipeline("outer", build)
+	p.AddPipeline(pipeline)
+	if !waitStatus(build, JobRunning, time.Second) {
+		t.Errorf("unexpected build status %d", build.GetStatus())
+	}
+	pipeline.Cancle()
+	result, err := pipeline.Wait(context.Background())
+	if err != nil || result.Status != PipelineCancled || build.GetStatus() != JobCancled {
+		t.Errorf("unexpected result %+v %v", result, err)
+	}
+
+	timeout := NewPipelineJob("timeout", sub).WithTimeout(30 * time.Millisecond)
+	future, _ := p.SubmitJob(timeout)
+	if _, err := future.Wait(); err != ErrJobTimeout || timeout.GetStatus() != JobTimeout {
+		t.Errorf("timeout status %d error %v", timeout.GetStatus(), err)
+	}
+	p.Close("finish")
+}
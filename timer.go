@@ -0,0 +1,168 @@
+package gopool
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DEFAULT_TIMER_TICK the precision of delayed jobs
+	DEFAULT_TIMER_TICK = 10 * time.Millisecond
+	// DEFAULT_TIMER_SLOTS the number of timer wheel slots
+	DEFAULT_TIMER_SLOTS = 512
+)
+
+// ErrJobScheduled the job already scheduled and not fired
+var ErrJobScheduled = errors.New("job already scheduled")
+
+// timerEntry the scheduled job in timer wheel
+type timerEntry struct {
+	job  *Job
+	slot int
+	// the number of wheel rounds left before fire
+	rounds int
+	// the scheduled time, the wheel may tick soon after the job added
+	deadline time.Time
+}
+
+// timerWheel hashed timing wheel, the expire function is
+// called with the job when it's scheduled time arrive
+type timerWheel struct {
+	tick    time.Duration
+	slots   []map[*timerEntry]struct{}
+	entries map[*Job]*timerEntry
+	// the current slot
+	pos     int
+	running bool
+	expire  func(job *Job)
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	m       sync.Mutex
+}
+
+func newTimerWheel(tick time.Duration, slots int, expire func(job *Job)) *timerWheel {
+	wheel := &timerWheel{
+		tick:    tick,
+		slots:   make([]map[*timerEntry]struct{}, slots),
+		entries: make(map[*Job]*timerEntry),
+		expire:  expire,
+		stop:    make(chan struct{}),
+	}
+	for i := range wheel.slots {
+		wheel.slots[i] = make(map[*timerEntry]struct{})
+	}
+	return wheel
+}
+
+// add schedule the job fire after delay, the wheel goroutine
+// is started when needed and exit when no job scheduled
+func (w *timerWheel) add(delay time.Duration, job *Job) error {
+	ticks := int((delay + w.tick - 1) / w.tick)
+	if ticks <= 0 {
+		w.expire(job)
+		return nil
+	}
+	w.m.Lock()
+	defer w.m.Unlock()
+	if _, ok := w.entries[job]; ok {
+		return ErrJobScheduled
+	}
+	entry := &timerEntry{
+		job:      job,
+		slot:     (w.pos + ticks) % len(w.slots),
+		rounds:   (ticks - 1) / len(w.slots),
+		deadline: time.Now().Add(delay),
+	}
+	w.slots[entry.slot][entry] = struct{}{}
+	w.entries[job] = entry
+	if !w.running {
+		w.running = true
+		w.wg.Add(1)
+		go w.run()
+	}
+	return nil
+}
+
+// remove cancel the scheduled job, return false if the job not scheduled
+func (w *timerWheel) remove(job *Job) bool {
+	w.m.Lock()
+	defer w.m.Unlock()
+	entry, ok := w.entries[job]
+	if !ok {
+		return false
+	}
+	delete(w.slots[entry.slot], entry)
+	delete(w.entries, job)
+	return true
+}
+
+func (w *timerWheel) len() int {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return len(w.entries)
+}
+
+// close stop the wheel goroutine and return the jobs not fired
+func (w *timerWheel) close() []*Job {
+	w.m.Lock()
+	close(w.stop)
+	var jobs []*Job
+	for job, entry := range w.entries {
+		delete(w.slots[entry.slot], entry)
+		jobs = append(jobs, job)
+	}
+	w.entries = make(map[*Job]*timerEntry)
+	w.m.Unlock()
+	// wait the fired jobs handled
+	w.wg.Wait()
+	return jobs
+}
+
+func (w *timerWheel) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			for _, job := range w.advance() {
+				w.expire(job)
+			}
+			w.m.Lock()
+			if len(w.entries) == 0 {
+				w.running = false
+				w.m.Unlock()
+				return
+			}
+			w.m.Unlock()
+		}
+	}
+}
+
+// advance move to the next slot and return the expired jobs
+func (w *timerWheel) advance() []*Job {
+	w.m.Lock()
+	defer w.m.Unlock()
+	var expired []*Job
+	now := time.Now()
+	w.pos = (w.pos + 1) % len(w.slots)
+	for entry := range w.slots[w.pos] {
+		if entry.rounds > 0 {
+			entry.rounds--
+			continue
+		}
+		delete(w.slots[w.pos], entry)
+		// not reach the scheduled time, wait the next tick
+		if now.Before(entry.deadline) {
+			entry.slot = (w.pos + 1) % len(w.slots)
+			w.slots[entry.slot][entry] = struct{}{}
+			continue
+		}
+		delete(w.entries, entry.job)
+		expired = append(expired, entry.job)
+	}
+	return expired
+}
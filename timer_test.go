@@ -0,0 +1,97 @@
+package gopool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerWheelRounds(t *testing.T) {
+	fired := make(chan *Job, 2)
+	wheel := newTimerWheel(time.Millisecond, 4, func(job *Job) {
+		fired <- job
+	})
+	start := time.Now()
+	wheel.add(10*time.Millisecond, NewJob("later", &testJob{}))
+	wheel.add(2*time.Millisecond, NewJob("sooner", &testJob{}))
+	if job := <-fired; job.Name != "sooner" {
+		t.Errorf("fired %s, want sooner", job.Name)
+	}
+	if job := <-fired; job.Name != "later" || time.Since(start) < 10*time.Millisecond {
+		t.Errorf("fired %s after %s", job.Name, time.Since(start))
+	}
+	if wheel.len() != 0 {
+		t.Errorf("wheel len %d, want 0", wheel.len())
+	}
+	wheel.close()
+}
+
+func TestTimerWheelRunning(t *testing.T) {
+	type fire struct {
+		job *Job
+		at  time.Time
+	}
+	fired := make(chan fire, 5)
+	wheel := newTimerWheel(10*time.Millisecond, 8, func(job *Job) {
+		fired <- fire{job, time.Now()}
+	})
+	// keep the wheel running
+	wheel.add(time.Hour, NewJob("keeper", &testJob{}))
+	for i := 0; i < 5; i++ {
+		time.Sleep(7 * time.Millisecond)
+		start := time.Now()
+		wheel.add(25*time.Millisecond, NewJob("delayed", &testJob{}))
+		if f := <-fired; f.at.Sub(start) < 25*time.Millisecond {
+			t.Errorf("job fired after %s, want 25ms", f.at.Sub(start))
+		}
+	}
+	if wheel.len() != 1 {
+		t.Errorf("wheel len %d, want 1", wheel.len())
+	}
+	wheel.close()
+}
+
+func TestPoolAddJobAfter(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewJob("delayed", &testJob{})
+	start := time.Now()
+	if err := p.AddJobAfter(50*time.Millisecond, job); err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if p.ScheduledJobs() != 1 || p.PenddingJobs() != 0 {
+		t.Errorf("scheduled %d pendding %d", p.ScheduledJobs(), p.PenddingJobs())
+	}
+	if err := p.AddJobAfter(50*time.Millisecond, job); err != ErrJobScheduled {
+		t.Errorf("unexpected error %v", err)
+	}
+	if !waitStatus(job, JobSuccess, time.Second) {
+		t.Error("job not executed")
+		t.FailNow()
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Errorf("job executed after %s", time.Since(start))
+	}
+	p.Close("finish")
+}
+
+func TestPoolCancleScheduled(t *testing.T) {
+	p := NewPool(10, 2)
+	job := NewJob("delayed", &testJob{})
+	p.AddJobAt(time.Now().Add(time.Hour), job)
+	if !p.CancleScheduled(job) {
+		t.Error("cancle scheduled job fail")
+	}
+	if p.ScheduledJobs() != 0 || job.GetStatus() != JobCancled {
+		t.Errorf("scheduled %d status %d", p.ScheduledJobs(), job.GetStatus())
+	}
+	if p.CancleScheduled(job) {
+		t.Error("cancle job not scheduled")
+	}
+
+	closed := NewJob("closed", &testJob{})
+	p.AddJobAfter(time.Hour, closed)
+	p.Close("finish")
+	if p.ScheduledJobs() != 0 || closed.GetStatus() != JobCancled {
+		t.Errorf("scheduled %d status %d", p.ScheduledJobs(), closed.GetStatus())
+	}
+}
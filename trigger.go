@@ -0,0 +1,111 @@
+package gopool
+
+// TriggerRule decide whether a job execute by the status of it's upstreams
+type TriggerRule int
+
+const (
+	// TriggerDefault no trigger rule, in pipeline the job is triggered
+	// once after all upstreams resolved and at least one of them executed,
+	// out of pipeline it's triggered every time one of it's upstreams finished
+	TriggerDefault TriggerRule = iota
+	// TriggerAllSuccess all upstreams success
+	TriggerAllSuccess
+	// TriggerAllDone all upstreams finished no matter the status
+	TriggerAllDone
+	// TriggerAllFailed all upstreams failed
+	TriggerAllFailed
+	// TriggerOneSuccess triggered as soon as one upstream success
+	TriggerOneSuccess
+	// TriggerOneFailed triggered as soon as one upstream failed
+	TriggerOneFailed
+	// TriggerNoneFailed all upstreams finished and none failed
+	TriggerNoneFailed
+	// TriggerNoneSkipped all upstreams finished and none skipped
+	TriggerNoneSkipped
+)
+
+func (r TriggerRule) String() string {
+	switch r {
+	case TriggerAllSuccess:
+		return "all_success"
+	case TriggerAllDone:
+		return "all_done"
+	case TriggerAllFailed:
+		return "all_failed"
+	case TriggerOneSuccess:
+		return "one_success"
+	case TriggerOneFailed:
+		return "one_failed"
+	case TriggerNoneFailed:
+		return "none_failed"
+	case TriggerNoneSkipped:
+		return "none_skipped"
+	}
+	return "default"
+}
+
+// upstreamStates the number of upstreams in each state,
+// failed include timeout and cancled
+type upstreamStates struct {
+	total, success, failed, skipped int
+}
+
+func (s upstreamStates) done() int {
+	return s.success + s.failed + s.skipped
+}
+
+// countUpstreams count the upstreams by status, when resolved
+// the upstreams not executed out of pipeline are counted as skipped
+func countUpstreams(upstreams []*Job, resolved bool) upstreamStates {
+	states := upstreamStates{total: len(upstreams)}
+	for _, job := range upstreams {
+		switch job.GetStatus() {
+		case JobSuccess:
+			states.success++
+		case JobFail, JobTimeout, JobCancled:
+			states.failed++
+		case JobSkipped:
+			states.skipped++
+		default:
+			if resolved {
+				states.skipped++
+			}
+		}
+	}
+	return states
+}
+
+// evaluate whether the rule decided by the upstreams and whether the
+// job should execute, the rule is decided when all upstreams resolved,
+// one success and one failed can be decided earlier
+func (r TriggerRule) evaluate(upstreams []*Job, resolved bool) (decided bool, run bool) {
+	states := countUpstreams(upstreams, resolved)
+	switch r {
+	case TriggerOneSuccess:
+		if states.success > 0 {
+			return true, true
+		}
+	case TriggerOneFailed:
+		if states.failed > 0 {
+			return true, true
+		}
+	}
+	if states.done() < states.total {
+		return false, false
+	}
+	switch r {
+	case TriggerAllSuccess:
+		return true, states.success == states.total
+	case TriggerAllFailed:
+		return true, states.failed == states.total
+	case TriggerNoneFailed:
+		return true, states.failed == 0
+	case TriggerNoneSkipped:
+		return true, states.skipped == 0
+	case TriggerOneSuccess, TriggerOneFailed:
+		return true, false
+	case TriggerDefault:
+		return true, states.success+states.failed > 0
+	}
+	return true, true
+}
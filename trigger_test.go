@@ -0,0 +1,80 @@
+package gopool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func newStatusJob(name string, status int) *Job {
+	job := NewJob(name, &testJob{})
+	job.status = status
+	return job
+}
+
+func TestTriggerRuleEvaluate(t *testing.T) {
+	success := newStatusJob("success", JobSuccess)
+	failed := newStatusJob("failed", JobFail)
+	timeout := newStatusJob("timeout", JobTimeout)
+	running := newStatusJob("running", JobRunning)
+	for _, c := range []struct {
+		rule      TriggerRule
+		upstreams []*Job
+		decided   bool
+		run       bool
+	}{
+		{TriggerAllSuccess, []*Job{success, success}, true, true},
+		{TriggerAllSuccess, []*Job{success, failed}, true, false},
+		{TriggerAllSuccess, []*Job{success, running}, false, false},
+		{TriggerAllDone, []*Job{success, failed}, true, true},
+		{TriggerAllFailed, []*Job{timeout, failed}, true, true},
+		{TriggerAllFailed, []*Job{success, failed}, true, false},
+		{TriggerOneSuccess, []*Job{success, running}, true, true},
+		{TriggerOneSuccess, []*Job{failed, running}, false, false},
+		{TriggerOneSuccess, []*Job{failed, failed}, true, false},
+		{TriggerOneFailed, []*Job{timeout, running}, true, true},
+		{TriggerNoneFailed, []*Job{success, success}, true, true},
+		{TriggerNoneFailed, []*Job{success, failed}, true, false},
+		{TriggerNoneSkipped, []*Job{success, failed}, true, true},
+	} {
+		decided, run := c.rule.evaluate(c.upstreams, false)
+		if decided != c.decided || run != c.run {
+			t.Errorf("%s %v: decided %v run %v", c.rule, jobs(c.upstreams), decided, run)
+		}
+	}
+}
+
+func TestTriggerRulePipeline(t *testing.T) {
+	var allDoneCount int32
+	p := NewPool(10, 3)
+	ok := NewJob("ok", &testJob{})
+	fail := NewJob("fail", &failJob{})
+	allSuccess := NewJob("allSuccess", &testJob{}).WithTriggerRule(TriggerAllSuccess)
+	oneFailed := NewJob("oneFailed", &testJob{}).WithTriggerRule(TriggerOneFailed)
+	allDone := NewJob("allDone", &testJob{}).WithTriggerRule(TriggerAllDone).
+		WithResultCallback(func(result interface{}, err error) {
+			atomic.AddInt32(&allDoneCount, 1)
+		})
+	for _, job := range []*Job{allSuccess, oneFailed, allDone} {
+		if err := job.After(ok, fail); err != nil {
+			t.Error(err.Error())
+			t.FailNow()
+		}
+	}
+	pipeline, err := NewPipeline("trigger", ok, fail, allSuccess, oneFailed, allDone)
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	p.AddPipeline(pipeline)
+	if _, err := pipeline.Wait(context.Background()); err != nil {
+		t.Error(err.Error())
+	}
+	if allSuccess.GetStatus() != JobSkipped || oneFailed.GetStatus() != JobSuccess {
+		t.Errorf("allSuccess status %d, oneFailed status %d", allSuccess.GetStatus(), oneFailed.GetStatus())
+	}
+	if atomic.LoadInt32(&allDoneCount) != 1 {
+		t.Errorf("allDone executed %d times", allDoneCount)
+	}
+	p.Close("finish")
+}
@@ -0,0 +1,112 @@
+package gopool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// typedHandler adapt the typed handler function to ContextJobHandler
+type typedHandler[T any] struct {
+	handler func(ctx context.Context) (T, error)
+}
+
+// Handle call the typed handler function
+func (h *typedHandler[T]) Handle(ctx context.Context) (interface{}, error) {
+	return h.handler(ctx)
+}
+
+// typedResult convert the result to T, zero value if not T
+func typedResult[T any](result interface{}, err error) (T, error) {
+	typed, _ := result.(T)
+	return typed, err
+}
+
+// TypedJob the job which handler return a result of type T,
+// all methods of Job are available
+type TypedJob[T any] struct {
+	*Job
+}
+
+// NewTypedJob get a new job which handler return a result of type T
+func NewTypedJob[T any](name string, handler func(ctx context.Context) (T, error)) *TypedJob[T] {
+	return &TypedJob[T]{Job: NewContextJob(name, &typedHandler[T]{handler: handler})}
+}
+
+// WithResultCallback typed result callback function
+func (j *TypedJob[T]) WithResultCallback(handler func(T, error)) *TypedJob[T] {
+	j.Job.WithResultCallback(func(result interface{}, err error) {
+		handler(typedResult[T](result, err))
+	})
+	return j
+}
+
+// GetResult get the typed result of job
+func (j *TypedJob[T]) GetResult() (T, error) {
+	return typedResult[T](j.Job.GetResult())
+}
+
+// TypedFuture the typed result handle of a submitted job
+type TypedFuture[T any] struct {
+	future *Future
+}
+
+// Submit add the typed handler function into pool as a job,
+// return the typed future of the result
+func Submit[T any](pool *Pool, handler func(ctx context.Context) (T, error)) (*TypedFuture[T], error) {
+	name := fmt.Sprintf("submit-%d", atomic.AddUint64(&submitted, 1))
+	return SubmitTyped(pool, NewTypedJob(name, handler))
+}
+
+// SubmitTyped add the typed job into pool, return the typed future of the result
+func SubmitTyped[T any](pool *Pool, job *TypedJob[T]) (*TypedFuture[T], error) {
+	future, err := pool.SubmitJob(job.Job)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedFuture[T]{future: future}, nil
+}
+
+// Future get the untyped future, used by WaitAll and WaitAny
+func (f *TypedFuture[T]) Future() *Future {
+	return f.future
+}
+
+// Job get the job of the future
+func (f *TypedFuture[T]) Job() *Job {
+	return f.future.Job()
+}
+
+// Done the channel closed when the job finished or cancled
+func (f *TypedFuture[T]) Done() <-chan struct{} {
+	return f.future.Done()
+}
+
+// Wait wait the job finished and get the typed result
+func (f *TypedFuture[T]) Wait() (T, error) {
+	return typedResult[T](f.future.Wait())
+}
+
+// WaitContext wait the job finished and get the typed result,
+// return the context error if the context done first
+func (f *TypedFuture[T]) WaitContext(ctx context.Context) (T, error) {
+	return typedResult[T](f.future.WaitContext(ctx))
+}
+
+// Result get the typed result without wait, see Future.Result
+func (f *TypedFuture[T]) Result() (T, error) {
+	return typedResult[T](f.future.Result())
+}
+
+// Cancle cancle the job to execute, the context of
+// the running job will be cancelled
+func (f *TypedFuture[T]) Cancle() {
+	f.future.Cancle()
+}
+
+// InputResult get the typed result of the upstream job,
+// false if not found or not type T
+func InputResult[T any](inputs Inputs, name string) (T, bool) {
+	result, ok := inputs[name].Result.(T)
+	return result, ok
+}
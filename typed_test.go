@@ -0,0 +1,66 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmitTyped(t *testing.T) {
+	p := NewPool(10, 2)
+	future, err := Submit(p, func(ctx context.Context) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+	if err != nil {
+		t.Error(err.Error())
+		t.FailNow()
+	}
+	if _, err := future.Result(); err != ErrJobNotDone {
+		t.Errorf("unexpected error %v", err)
+	}
+	result, err := future.Wait()
+	if result != 42 || err != nil {
+		t.Errorf("unexpected result %v %v", result, err)
+	}
+
+	failed, _ := Submit(p, func(ctx context.Context) (string, error) {
+		return "", errors.New("fail")
+	})
+	if err := WaitAll(context.Background(), future.Future(), failed.Future()); err != nil {
+		t.Error(err.Error())
+	}
+	if result, err := failed.Result(); result != "" || err == nil {
+		t.Errorf("unexpected result %v %v", result, err)
+	}
+	p.Close("finish")
+}
+
+func TestTypedJob(t *testing.T) {
+	p := NewPool(10, 2)
+	var callback []string
+	job := NewTypedJob("typed", func(ctx context.Context) ([]string, error) {
+		return []string{"a", "b"}, nil
+	}).WithResultCallback(func(result []string, err error) {
+		callback = result
+	})
+	length := NewTypedJob("length", func(ctx context.Context) (int, error) {
+		result, ok := InputResult[[]string](InputsFromContext(ctx), "typed")
+		if !ok {
+			return 0, errors.New("input not found")
+		}
+		return len(result), nil
+	})
+	length.After(job.Job)
+	pipeline, _ := NewPipeline("typed", job.Job)
+	p.AddPipeline(pipeline)
+	pipeline.Wait(context.Background())
+	if result, err := job.GetResult(); len(result) != 2 || err != nil || len(callback) != 2 {
+		t.Errorf("unexpected result %v %v callback %v", result, err, callback)
+	}
+	if result, err := length.GetResult(); result != 2 || err != nil {
+		t.Errorf("unexpected length %v %v", result, err)
+	}
+	p.Close("finish")
+}
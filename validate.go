@@ -0,0 +1,247 @@
+package gopool
+
+import "fmt"
+
+// FindingKind the kind of the problem found by Pipeline.Validate
+type FindingKind int
+
+const (
+	// FindingInvalidGraph the graph has no top jobs or has cycle
+	FindingInvalidGraph FindingKind = iota
+	// FindingDuplicateName many jobs in the graph have the same name
+	FindingDuplicateName
+	// FindingNotListed the job reachable but not listed in Jobs
+	FindingNotListed
+	// FindingUnreachable the job listed in Jobs but not reachable
+	// from the top jobs, it will never execute
+	FindingUnreachable
+	// FindingOnceOnJoin the job with many upstreams set once, a job in
+	// pipeline is triggered once after all upstreams resolved, once only
+	// take effect when the job executed out of pipeline
+	FindingOnceOnJoin
+	// FindingNilHandler the job has no handler
+	FindingNilHandler
+	// FindingMayNeverExecute the When condition of the job is false for
+	// all the upstreams status tried, or all upstreams may never execute
+	// and the trigger rule require some upstreams executed, the When
+	// condition may depend on other state so it's a warning
+	FindingMayNeverExecute
+)
+
+// the max number of upstreams to try all status combinations
+// when evaluate the When condition, only the same status of
+// all upstreams are tried for the jobs with more upstreams
+const maxWhenUpstreams = 3
+
+// the upstreams status tried when evaluate the When condition
+var whenUpstreamStatus = []int{JobSuccess, JobFail, JobTimeout, JobCancled, JobSkipped}
+
+func (k FindingKind) String() string {
+	switch k {
+	case FindingDuplicateName:
+		return "duplicate name"
+	case FindingNotListed:
+		return "not listed"
+	case FindingUnreachable:
+		return "unreachable"
+	case FindingOnceOnJoin:
+		return "once on join"
+	case FindingNilHandler:
+		return "nil handler"
+	case FindingMayNeverExecute:
+		return "may never execute"
+	}
+	return "invalid graph"
+}
+
+// Finding a problem of the pipeline
+type Finding struct {
+	Kind FindingKind
+	// the name of the job, empty if the problem is about the pipeline
+	Job string
+	Msg string
+	// the problem may not prevent the pipeline executing as expected
+	Warning bool
+}
+
+func (f Finding) String() string {
+	if f.Job == "" {
+		return fmt.Sprintf("%s: %s", f.Kind, f.Msg)
+	}
+	return fmt.Sprintf("%s: job '%s' %s", f.Kind, f.Job, f.Msg)
+}
+
+// Validate check the pipeline define, return the problems found, nil
+// if no problem, the When conditions are called with the copies of the
+// jobs which upstreams in different status
+func (p *Pipeline) Validate() []Finding {
+	var findings []Finding
+	topJobs, err := p.getTopJobs()
+	if err == nil {
+		err = p.isCycleAdded(topJobs)
+	}
+	if err != nil {
+		return append(findings, Finding{Kind: FindingInvalidGraph, Msg: err.Error()})
+	}
+
+	p.m.Lock()
+	var (
+		jobs      = append([]*Job{}, p.UniqueJobs...)
+		sorted    = topological(jobs)
+		reachable = make(map[*Job]bool, len(jobs))
+		listed    = make(map[*Job]bool, len(p.Jobs))
+		names     = make(map[string]int, len(jobs))
+	)
+	for _, job := range jobs {
+		reachable[job] = true
+	}
+	for _, job := range p.Jobs {
+		listed[job] = true
+		if !reachable[job] {
+			findings = append(findings, Finding{Kind: FindingUnreachable, Job: job.Name,
+				Msg: "listed but not reachable from the top jobs"})
+		}
+	}
+	p.m.Unlock()
+	// the cycle added after the pipeline created may
+	// not be reachable from the top jobs
+	if len(sorted) < len(jobs) {
+		return []Finding{{Kind: FindingInvalidGraph, Msg: "cycle added"}}
+	}
+
+	for _, job := range jobs {
+		if names[job.Name]++; names[job.Name] == 2 {
+			findings = append(findings, Finding{Kind: FindingDuplicateName, Job: job.Name,
+				Msg: "name used by many jobs"})
+		}
+	}
+	for _, job := range jobs {
+		if !listed[job] {
+			findings = append(findings, Finding{Kind: FindingNotListed, Job: job.Name,
+				Msg: "reachable but not listed in Jobs", Warning: true})
+		}
+		if job.handler == nil && job.sub == nil {
+			findings = append(findings, Finding{Kind: FindingNilHandler, Job: job.Name,
+				Msg: "has no handler"})
+		}
+		if job.IsOnce() && len(job.parents) > 1 {
+			findings = append(findings, Finding{Kind: FindingOnceOnJoin, Job: job.Name,
+				Msg: "set once but already triggered once after all upstreams resolved", Warning: true})
+		}
+	}
+	// check the upstreams before the job
+	never := make(map[*Job]bool)
+	for _, job := range sorted {
+		if mayNeverExecute(job, never) {
+			never[job] = true
+			findings = append(findings, Finding{Kind: FindingMayNeverExecute, Job: job.Name,
+				Msg: "may never execute by it's When condition or upstreams", Warning: true})
+		}
+	}
+	return findings
+}
+
+// topological sort the jobs so that the upstreams before the jobs
+func topological(jobs []*Job) []*Job {
+	var (
+		waiting = make(map[*Job]int, len(jobs))
+		sorted  []*Job
+	)
+	for _, job := range jobs {
+		waiting[job] = 0
+	}
+	for _, job := range jobs {
+		for _, children := range job.childrens {
+			if _, ok := waiting[children]; ok {
+				waiting[children]++
+			}
+		}
+	}
+	for _, job := range jobs {
+		if waiting[job] == 0 {
+			sorted = append(sorted, job)
+		}
+	}
+	for index := 0; index < len(sorted); index++ {
+		for _, children := range sorted[index].childrens {
+			if _, ok := waiting[children]; !ok {
+				continue
+			}
+			if waiting[children]--; waiting[children] == 0 {
+				sorted = append(sorted, children)
+			}
+		}
+	}
+	return sorted
+}
+
+// mayNeverExecute whether the job may never execute, the When condition is
+// false for all the upstreams status tried, or the trigger rule not satisfied
+// when all upstreams may never execute
+func mayNeverExecute(job *Job, never map[*Job]bool) bool {
+	if len(job.parents) > 0 {
+		all := true
+		for _, parent := range job.parents {
+			all = all && never[parent]
+		}
+		if all {
+			switch job.triggerRule {
+			case TriggerAllDone, TriggerNoneFailed:
+			default:
+				return true
+			}
+		}
+	}
+	if job.when == nil || len(job.parents) == 0 {
+		return false
+	}
+	for _, status := range upstreamStatus(len(job.parents)) {
+		if whenWithUpstreams(job, status) {
+			return false
+		}
+	}
+	return true
+}
+
+// upstreamStatus the status combinations of the upstreams to try
+func upstreamStatus(upstreams int) [][]int {
+	var combinations [][]int
+	if upstreams > maxWhenUpstreams {
+		for _, status := range whenUpstreamStatus {
+			combination := make([]int, upstreams)
+			for index := range combination {
+				combination[index] = status
+			}
+			combinations = append(combinations, combination)
+		}
+		return combinations
+	}
+	combinations = [][]int{{}}
+	for index := 0; index < upstreams; index++ {
+		var next [][]int
+		for _, combination := range combinations {
+			for _, status := range whenUpstreamStatus {
+				next = append(next, append(append([]int{}, combination...), status))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// whenWithUpstreams evaluate the When condition of the copy of the job
+// which upstreams in the status, true if the condition panic
+func whenWithUpstreams(job *Job, status []int) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = true
+		}
+	}()
+	clone := job.clone()
+	for index, parent := range job.parents {
+		parentClone := parent.clone()
+		parentClone.status = status[index]
+		clone.parents = append(clone.parents, parentClone)
+	}
+	return job.when(clone)
+}
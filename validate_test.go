@@ -0,0 +1,87 @@
+package gopool
+
+import (
+	"testing"
+)
+
+func TestPipelineValidate(t *testing.T) {
+	pipeline := newDiamondPipeline(t)
+	if findings := pipeline.Validate(); len(findings) != 3 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	for _, job := range pipeline.UniqueJobs {
+		pipeline.Jobs = append(pipeline.Jobs, job)
+	}
+	if findings := pipeline.Validate(); findings != nil {
+		t.Errorf("unexpected findings %v", findings)
+	}
+
+	top := NewJob("top", &testJob{})
+	never := NewJob("never", &testJob{}).When(func(self *Job) bool { return false })
+	depend := NewJob("depend", &testJob{}).When(next)
+	after := NewJob("after", &testJob{})
+	done := NewJob("done", &testJob{}).WithTriggerRule(TriggerAllDone)
+	join := NewJob("join", nil).WithOnce()
+	dumplicate := NewJob("top", &testJob{})
+	orphan := NewJob("orphan", &testJob{})
+	orphanParent := NewJob("orphan parent", &testJob{})
+	top.Before(never, depend, dumplicate)
+	after.After(never)
+	done.After(never)
+	join.After(depend, after)
+	orphan.After(orphanParent)
+	pipeline, _ = NewPipeline("validate", top, never, depend, after, done, join, dumplicate, orphan)
+
+	want := map[string]FindingKind{
+		"orphan": FindingUnreachable,
+		"top":    FindingDuplicateName,
+		"never":  FindingMayNeverExecute,
+		"after":  FindingMayNeverExecute,
+		"join":   FindingOnceOnJoin,
+	}
+	findings := pipeline.Validate()
+	kinds := make(map[FindingKind]int)
+	for _, finding := range findings {
+		kinds[finding.Kind]++
+		if finding.Kind == FindingNilHandler {
+			if finding.Job != "join" {
+				t.Errorf("unexpected finding %s", finding)
+			}
+			continue
+		}
+		if kind, ok := want[finding.Job]; !ok || kind != finding.Kind {
+			t.Errorf("unexpected finding %s", finding)
+		}
+	}
+	if len(findings) != 6 || kinds[FindingMayNeverExecute] != 2 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+
+	never.Before(top)
+	if findings := pipeline.Validate(); len(findings) != 1 || findings[0].Kind != FindingInvalidGraph {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
+
+func TestPipelineValidateWhen(t *testing.T) {
+	top := NewJob("top", &testJob{})
+	other := NewJob("other", &testJob{})
+	timeout := NewJob("timeout", &testJob{}).When(func(self *Job) bool {
+		return self.GetUpstreams()[0].GetStatus() == JobTimeout
+	})
+	mixed := NewJob("mixed", &testJob{}).When(func(self *Job) bool {
+		upstreams := self.GetUpstreams()
+		return upstreams[0].GetStatus() == JobSuccess && upstreams[1].GetStatus() == JobCancled
+	})
+	captured := NewJob("captured", &testJob{})
+	captured.When(func(self *Job) bool { return top.GetStatus() == JobTimeout })
+	top.Before(timeout, mixed, captured)
+	other.Before(mixed)
+	pipeline, _ := NewPipeline("when", top, other, timeout, mixed, captured)
+
+	findings := pipeline.Validate()
+	if len(findings) != 1 || findings[0].Job != "captured" ||
+		findings[0].Kind != FindingMayNeverExecute || !findings[0].Warning {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}